@@ -0,0 +1,225 @@
+// Copyright 2023 IronCore authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ironcore-dev/controller-utils/unstructuredutils"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// ApplyConflict is a conflict of a server-side apply request with another field manager.
+type ApplyConflict struct {
+	// Manager is the name of the field manager owning the conflicting field.
+	// If the manager could not be determined from the message, it is empty.
+	Manager string
+	// Field is the path of the conflicting field, e.g. '.data.foo'.
+	Field string
+	// Message is the message reported by the server for the conflict.
+	Message string
+}
+
+// ApplyConflictsFromError extracts all ApplyConflict from the given error.
+// If the error is not an apply conflict error, nil is returned.
+func ApplyConflictsFromError(err error) []ApplyConflict {
+	var status apierrors.APIStatus
+	if !errors.As(err, &status) || !apierrors.IsConflict(err) {
+		return nil
+	}
+
+	details := status.Status().Details
+	if details == nil {
+		return nil
+	}
+
+	var conflicts []ApplyConflict
+	for _, cause := range details.Causes {
+		if cause.Type != metav1.CauseTypeFieldManagerConflict {
+			continue
+		}
+
+		conflicts = append(conflicts, ApplyConflict{
+			Manager: parseConflictManager(cause.Message),
+			Field:   cause.Field,
+			Message: cause.Message,
+		})
+	}
+	return conflicts
+}
+
+// parseConflictManager parses the manager name out of a conflict message in the form of
+// 'conflict with "<manager>"[ with subresource "<subresource>"][ using <apiVersion>[ at <time>]]'.
+func parseConflictManager(msg string) string {
+	rest, ok := strings.CutPrefix(msg, "conflict with ")
+	if !ok {
+		return ""
+	}
+
+	quoted, err := strconv.QuotedPrefix(rest)
+	if err != nil {
+		return ""
+	}
+
+	manager, err := strconv.Unquote(quoted)
+	if err != nil {
+		return ""
+	}
+	return manager
+}
+
+// ApplyConflictError is returned if a server-side apply failed due to conflicts with other field managers.
+// It unwraps to the original error reported by the server.
+type ApplyConflictError struct {
+	// Key is the key of the object that could not be applied.
+	Key client.ObjectKey
+	// Conflicts are the conflicts reported by the server.
+	Conflicts []ApplyConflict
+	// Err is the original error.
+	Err error
+}
+
+// Error implements error.
+func (e *ApplyConflictError) Error() string {
+	fields := make([]string, 0, len(e.Conflicts))
+	for _, conflict := range e.Conflicts {
+		fields = append(fields, fmt.Sprintf("%s (%s)", conflict.Field, conflict.Manager))
+	}
+	return fmt.Sprintf("error applying object %s: %d conflict(s): [%s]",
+		e.Key,
+		len(e.Conflicts),
+		strings.Join(fields, ", "),
+	)
+}
+
+// Unwrap returns the original error.
+func (e *ApplyConflictError) Unwrap() error {
+	return e.Err
+}
+
+// SanitizeForApply removes all fields from the given object that must not be sent with a server-side apply request,
+// namely managed fields, the resource version and the status.
+//
+// This is useful for objects read from files, e.g. exported from a live cluster.
+func SanitizeForApply(obj *unstructured.Unstructured) {
+	obj.SetManagedFields(nil)
+	obj.SetResourceVersion("")
+	unstructured.RemoveNestedField(obj.Object, "status")
+}
+
+func setGroupVersionKindForApply(c client.Client, obj client.Object) error {
+	if !obj.GetObjectKind().GroupVersionKind().Empty() {
+		return nil
+	}
+
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return fmt.Errorf("error getting kind of object %s: %w", client.ObjectKeyFromObject(obj), err)
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+	return nil
+}
+
+// applyConflictClient is a client that converts apply conflict errors to *ApplyConflictError when patching.
+type applyConflictClient struct {
+	client.Client
+}
+
+// Patch implements client.Client.
+func (c applyConflictClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if err := c.Client.Patch(ctx, obj, patch, opts...); err != nil {
+		if conflicts := ApplyConflictsFromError(err); conflicts != nil {
+			return &ApplyConflictError{
+				Key:       client.ObjectKeyFromObject(obj),
+				Conflicts: conflicts,
+				Err:       err,
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// ServerSideApply applies the given object via server-side apply using the given field manager.
+//
+// If any field conflicts with another field manager, an *ApplyConflictError is returned.
+// To take over ownership of conflicting fields instead, specify client.ForceOwnership.
+// If the given object is typed and has no group version kind set, it is determined using the client scheme.
+func ServerSideApply(ctx context.Context, c client.Client, obj client.Object, fieldManager string, opts ...client.PatchOption) error {
+	if fieldManager == "" {
+		return fmt.Errorf("must specify field manager")
+	}
+	if err := setGroupVersionKindForApply(c, obj); err != nil {
+		return err
+	}
+
+	opts = append([]client.PatchOption{client.FieldOwner(fieldManager)}, opts...)
+	return applyConflictClient{c}.Patch(ctx, obj, client.Apply, opts...)
+}
+
+// ServerSideApplyMultiple applies the given objects via server-side apply using the given field manager.
+// The objects are applied using PatchMultiple, thus any MultipleOption can be specified alongside the
+// patch options.
+//
+// If any field of an object conflicts with another field manager, an *ApplyConflictError is reported for the object.
+// To take over ownership of conflicting fields instead, specify client.ForceOwnership.
+// If a given object is typed and has no group version kind set, it is determined using the client scheme.
+func ServerSideApplyMultiple(ctx context.Context, c client.Client, objs []client.Object, fieldManager string, opts ...client.PatchOption) error {
+	if fieldManager == "" {
+		return fmt.Errorf("must specify field manager")
+	}
+	for _, obj := range objs {
+		if err := setGroupVersionKindForApply(c, obj); err != nil {
+			return err
+		}
+	}
+
+	opts = append([]client.PatchOption{client.FieldOwner(fieldManager)}, opts...)
+	reqs := PatchRequestsFromObjectsAndProvider(objs, ApplyAll)
+	return PatchMultiple(ctx, applyConflictClient{c}, reqs, opts...)
+}
+
+// ServerSideApplyFromFile reads the given file as unstructured objects, sanitizes them using SanitizeForApply
+// and applies them using ServerSideApplyMultiple.
+// The returned unstructured.Unstructured objects contain the result of applying them.
+func ServerSideApplyFromFile(
+	ctx context.Context,
+	c client.Client,
+	filename string,
+	fieldManager string,
+	opts ...client.PatchOption,
+) ([]unstructured.Unstructured, error) {
+	objs, err := unstructuredutils.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+
+	for i := range objs {
+		SanitizeForApply(&objs[i])
+	}
+
+	if err := ServerSideApplyMultiple(ctx, c, unstructuredutils.UnstructuredSliceToObjectSliceNoCopy(objs), fieldManager, opts...); err != nil {
+		return nil, err
+	}
+	return objs, nil
+}
@@ -0,0 +1,175 @@
+// Copyright 2023 IronCore authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientutils_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	. "github.com/ironcore-dev/controller-utils/clientutils"
+	mockclient "github.com/ironcore-dev/controller-utils/mock/controller-runtime/client"
+	"github.com/ironcore-dev/controller-utils/testdata"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Apply", func() {
+	const fieldManager = "my-manager"
+
+	var (
+		ctx  context.Context
+		ctrl *gomock.Controller
+
+		c *mockclient.MockClient
+
+		cm          *corev1.ConfigMap
+		conflictErr error
+	)
+	BeforeEach(func() {
+		ctx = context.Background()
+		ctrl = gomock.NewController(GinkgoT())
+
+		c = mockclient.NewMockClient(ctrl)
+		c.EXPECT().Scheme().Return(scheme.Scheme).AnyTimes()
+
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: corev1.NamespaceDefault,
+				Name:      "my-cm",
+			},
+		}
+
+		conflictErr = apierrors.NewApplyConflict([]metav1.StatusCause{
+			{
+				Type:    metav1.CauseTypeFieldManagerConflict,
+				Message: `conflict with "other-manager" using v1`,
+				Field:   ".data.foo",
+			},
+			{
+				Type:    metav1.CauseTypeFieldManagerConflict,
+				Message: `conflict with "another-manager" with subresource "status"`,
+				Field:   ".data.bar",
+			},
+		}, "Apply failed with 2 conflicts")
+	})
+
+	Describe("ApplyConflictsFromError", func() {
+		It("should extract the conflicts from an apply conflict error", func() {
+			Expect(ApplyConflictsFromError(fmt.Errorf("wrapped: %w", conflictErr))).To(Equal([]ApplyConflict{
+				{
+					Manager: "other-manager",
+					Field:   ".data.foo",
+					Message: `conflict with "other-manager" using v1`,
+				},
+				{
+					Manager: "another-manager",
+					Field:   ".data.bar",
+					Message: `conflict with "another-manager" with subresource "status"`,
+				},
+			}))
+		})
+
+		It("should return nil for any other error", func() {
+			Expect(ApplyConflictsFromError(fmt.Errorf("some error"))).To(BeNil())
+			Expect(ApplyConflictsFromError(apierrors.NewConflict(corev1.Resource("configmaps"), "my-cm", fmt.Errorf("some error")))).To(BeNil())
+		})
+	})
+
+	Describe("SanitizeForApply", func() {
+		It("should remove managed fields, resource version and status", func() {
+			u := testdata.UnstructuredConfigMap()
+			u.SetResourceVersion("1")
+			u.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: "other-manager"}})
+			Expect(unstructured.SetNestedField(u.Object, "foo", "status", "phase")).To(Succeed())
+
+			SanitizeForApply(u)
+			Expect(u).To(Equal(testdata.UnstructuredConfigMap()))
+		})
+	})
+
+	Describe("ServerSideApply", func() {
+		It("should apply the object with the field manager and its group version kind set", func() {
+			c.EXPECT().Patch(ctx, cm, client.Apply, client.FieldOwner(fieldManager), client.ForceOwnership).Do(
+				func(_ context.Context, obj client.Object, _ client.Patch, _ ...client.PatchOption) {
+					Expect(obj.GetObjectKind().GroupVersionKind()).To(Equal(corev1.SchemeGroupVersion.WithKind("ConfigMap")))
+				})
+
+			Expect(ServerSideApply(ctx, c, cm, fieldManager, client.ForceOwnership)).To(Succeed())
+		})
+
+		It("should error if no field manager is specified", func() {
+			Expect(ServerSideApply(ctx, c, cm, "")).To(MatchError("must specify field manager"))
+		})
+
+		It("should report conflicts as *ApplyConflictError", func() {
+			c.EXPECT().Patch(ctx, cm, client.Apply, client.FieldOwner(fieldManager)).Return(conflictErr)
+
+			err := ServerSideApply(ctx, c, cm, fieldManager)
+			Expect(err).To(MatchError("error applying object default/my-cm: 2 conflict(s): [.data.foo (other-manager), .data.bar (another-manager)]"))
+			Expect(apierrors.IsConflict(err)).To(BeTrue())
+
+			var applyErr *ApplyConflictError
+			Expect(errors.As(err, &applyErr)).To(BeTrue())
+			Expect(applyErr.Key).To(Equal(client.ObjectKeyFromObject(cm)))
+			Expect(applyErr.Conflicts).To(HaveLen(2))
+		})
+	})
+
+	Describe("ServerSideApplyMultiple", func() {
+		It("should report conflicts of each object", func() {
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: corev1.NamespaceDefault,
+					Name:      "my-secret",
+				},
+			}
+			gomock.InOrder(
+				c.EXPECT().Patch(ctx, cm, client.Apply, client.FieldOwner(fieldManager)).Return(conflictErr),
+				c.EXPECT().Patch(ctx, secret, client.Apply, client.FieldOwner(fieldManager)),
+			)
+
+			err := ServerSideApplyMultiple(ctx, c, []client.Object{cm, secret}, fieldManager, ContinueOnError)
+
+			var multiErr *MultipleError
+			Expect(errors.As(err, &multiErr)).To(BeTrue())
+			Expect(multiErr.Errors).To(HaveLen(1))
+
+			var applyErr *ApplyConflictError
+			Expect(errors.As(multiErr.Errors[0].Err, &applyErr)).To(BeTrue())
+			Expect(applyErr.Key).To(Equal(client.ObjectKeyFromObject(cm)))
+		})
+	})
+
+	Describe("ServerSideApplyFromFile", func() {
+		It("should apply all objects from the file", func() {
+			gomock.InOrder(
+				c.EXPECT().Patch(ctx, testdata.UnstructuredSecret(), client.Apply, client.FieldOwner(fieldManager)),
+				c.EXPECT().Patch(ctx, testdata.UnstructuredConfigMap(), client.Apply, client.FieldOwner(fieldManager)),
+			)
+
+			objs, err := ServerSideApplyFromFile(ctx, c, "../testdata/bases/objects.yaml", fieldManager)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(objs).To(Equal(testdata.UnstructuredObjects()))
+		})
+	})
+})
@@ -0,0 +1,180 @@
+// Copyright 2023 IronCore authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientutils
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ironcore-dev/controller-utils/metautils"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// ReconcileChildrenResult summarizes the result of ReconcileChildren.
+type ReconcileChildrenResult struct {
+	// Created references the desired children that were not controlled by the owner before.
+	Created ObjectRefSet
+	// Patched references the existing children that were changed.
+	Patched ObjectRefSet
+	// Unchanged references the existing children that did not change.
+	Unchanged ObjectRefSet
+	// Deleted references the children that were not desired anymore and were deleted.
+	Deleted ObjectRefSet
+	// Conflicting references the desired objects that exist but are not controlled by the owner.
+	// They are left untouched.
+	Conflicting ObjectRefSet
+}
+
+// ReconcileChildren reconciles the children of the given owner to match the desired objects.
+//
+// The existing children are listed into the given list using ListAndFilterControlledBy with the given
+// client.ListOption. Desired objects that are not among them are checked for existence: Objects that exist
+// but are not controlled by the owner, as determined by metautils.IsControlledBy, are not adopted but reported
+// as conflicting. The controller reference of the owner is set on all other desired objects, which are then
+// applied via ServerSideApply using the given field manager. Only children already controlled by the owner
+// are applied with client.ForceOwnership. An existing child is considered changed if applying it changed
+// its resource version.
+// Finally, all existing children that are controlled by the owner but not desired are deleted.
+//
+// The returned ReconcileChildrenResult identifies children using ObjectRef. If any desired object is
+// conflicting, an error is returned after all other children have been reconciled. If any other error occurs,
+// the result up to the error is returned alongside the error.
+func ReconcileChildren(
+	ctx context.Context,
+	c client.Client,
+	owner client.Object,
+	list client.ObjectList,
+	desired []client.Object,
+	fieldManager string,
+	opts ...client.ListOption,
+) (ReconcileChildrenResult, error) {
+	res := ReconcileChildrenResult{
+		Created:     NewObjectRefSet(),
+		Patched:     NewObjectRefSet(),
+		Unchanged:   NewObjectRefSet(),
+		Deleted:     NewObjectRefSet(),
+		Conflicting: NewObjectRefSet(),
+	}
+
+	if err := ListAndFilterControlledBy(ctx, c, owner, list, opts...); err != nil {
+		return res, fmt.Errorf("error listing children: %w", err)
+	}
+
+	existing, err := metautils.ExtractList(list)
+	if err != nil {
+		return res, fmt.Errorf("error extracting children: %w", err)
+	}
+
+	existingRefs := NewObjectRefSet()
+	existingByRef := make(map[ObjectRef]client.Object, len(existing))
+	for _, child := range existing {
+		ref, err := ObjectRefFromObject(c.Scheme(), child)
+		if err != nil {
+			return res, err
+		}
+		existingRefs.Insert(ref)
+		existingByRef[ref] = child
+	}
+
+	desiredRefs := NewObjectRefSet()
+	for _, obj := range desired {
+		ref, err := ObjectRefFromObject(c.Scheme(), obj)
+		if err != nil {
+			return res, err
+		}
+		desiredRefs.Insert(ref)
+
+		child, ok := existingByRef[ref]
+		if !ok {
+			current, err := getUnlisted(ctx, c, obj)
+			if err != nil {
+				return res, fmt.Errorf("error getting %s %s: %w", ref.GroupKind, ref.Key, err)
+			}
+			if current != nil {
+				controlled, err := metautils.IsControlledBy(c.Scheme(), owner, current)
+				if err != nil {
+					return res, fmt.Errorf("error checking whether %s %s is controlled by owner: %w", ref.GroupKind, ref.Key, err)
+				}
+				if !controlled {
+					res.Conflicting.Insert(ref)
+					continue
+				}
+				child, ok = current, true
+			}
+		}
+
+		if err := controllerutil.SetControllerReference(owner, obj, c.Scheme()); err != nil {
+			return res, fmt.Errorf("error setting controller reference on %s %s: %w", ref.GroupKind, ref.Key, err)
+		}
+
+		var applyOpts []client.PatchOption
+		if ok {
+			applyOpts = append(applyOpts, client.ForceOwnership)
+		}
+		if err := ServerSideApply(ctx, c, obj, fieldManager, applyOpts...); err != nil {
+			return res, fmt.Errorf("error applying %s %s: %w", ref.GroupKind, ref.Key, err)
+		}
+
+		switch {
+		case !ok:
+			res.Created.Insert(ref)
+		case child.GetResourceVersion() != obj.GetResourceVersion():
+			res.Patched.Insert(ref)
+		default:
+			res.Unchanged.Insert(ref)
+		}
+	}
+
+	for _, ref := range existingRefs.List() {
+		if desiredRefs.Has(ref) {
+			continue
+		}
+
+		if _, err := DeleteIfExists(ctx, c, existingByRef[ref]); err != nil {
+			return res, fmt.Errorf("error deleting %s %s: %w", ref.GroupKind, ref.Key, err)
+		}
+		res.Deleted.Insert(ref)
+	}
+
+	if res.Conflicting.Len() > 0 {
+		return res, fmt.Errorf("refusing to adopt objects not controlled by owner: %v", res.Conflicting.List())
+	}
+	return res, nil
+}
+
+// getUnlisted gets the current state of the given desired object that was not listed as child.
+// If the object does not exist, nil is returned.
+func getUnlisted(ctx context.Context, c client.Client, obj client.Object) (client.Object, error) {
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := newObjectForGet(c.Scheme(), obj, gvk)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.Get(ctx, client.ObjectKeyFromObject(obj), current); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return current, nil
+}
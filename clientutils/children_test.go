@@ -0,0 +1,211 @@
+// Copyright 2023 IronCore authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientutils_test
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/ironcore-dev/controller-utils/clientutils"
+	mockclient "github.com/ironcore-dev/controller-utils/mock/controller-runtime/client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+var _ = Describe("Children", func() {
+	const fieldManager = "my-manager"
+
+	var (
+		ctx  context.Context
+		ctrl *gomock.Controller
+
+		c *mockclient.MockClient
+
+		owner *corev1.Secret
+	)
+	BeforeEach(func() {
+		ctx = context.Background()
+		ctrl = gomock.NewController(GinkgoT())
+
+		c = mockclient.NewMockClient(ctrl)
+		c.EXPECT().Scheme().Return(scheme.Scheme).AnyTimes()
+
+		owner = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: corev1.NamespaceDefault,
+				Name:      "owner",
+				UID:       "owner-uid",
+			},
+		}
+	})
+
+	newConfigMap := func(name, resourceVersion string, controlled bool) *corev1.ConfigMap {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:       corev1.NamespaceDefault,
+				Name:            name,
+				ResourceVersion: resourceVersion,
+			},
+		}
+		if controlled {
+			Expect(controllerutil.SetControllerReference(owner, cm, scheme.Scheme)).To(Succeed())
+		}
+		return cm
+	}
+
+	configMapRef := func(name string) ObjectRef {
+		return ObjectRef{
+			GroupKind: schema.GroupKind{Kind: "ConfigMap"},
+			Key:       client.ObjectKey{Namespace: corev1.NamespaceDefault, Name: name},
+		}
+	}
+
+	Describe("ReconcileChildren", func() {
+		It("should apply the desired children and delete the undesired ones", func() {
+			unchanged := newConfigMap("unchanged", "1", true)
+			changed := newConfigMap("changed", "1", true)
+			undesired := newConfigMap("undesired", "1", true)
+			other := newConfigMap("other", "1", false)
+
+			desiredUnchanged := newConfigMap("unchanged", "", false)
+			desiredChanged := newConfigMap("changed", "", false)
+			desiredNew := newConfigMap("new", "", false)
+
+			setResourceVersion := func(resourceVersion string) func(context.Context, client.Object, client.Patch, ...client.PatchOption) {
+				return func(_ context.Context, obj client.Object, _ client.Patch, _ ...client.PatchOption) {
+					Expect(metav1.IsControlledBy(obj, owner)).To(BeTrue())
+					obj.SetResourceVersion(resourceVersion)
+				}
+			}
+
+			gomock.InOrder(
+				c.EXPECT().List(ctx, gomock.AssignableToTypeOf(&corev1.ConfigMapList{}), client.InNamespace(corev1.NamespaceDefault)).Do(
+					func(_ context.Context, list client.ObjectList, _ ...client.ListOption) {
+						list.(*corev1.ConfigMapList).Items = []corev1.ConfigMap{*unchanged, *changed, *undesired, *other}
+					}),
+				c.EXPECT().Patch(ctx, desiredUnchanged, client.Apply, client.FieldOwner(fieldManager), client.ForceOwnership).
+					Do(setResourceVersion("1")),
+				c.EXPECT().Patch(ctx, desiredChanged, client.Apply, client.FieldOwner(fieldManager), client.ForceOwnership).
+					Do(setResourceVersion("2")),
+				c.EXPECT().Get(ctx, client.ObjectKeyFromObject(desiredNew), gomock.AssignableToTypeOf(&corev1.ConfigMap{})).
+					Return(apierrors.NewNotFound(corev1.Resource("configmaps"), desiredNew.Name)),
+				c.EXPECT().Patch(ctx, desiredNew, client.Apply, client.FieldOwner(fieldManager)).
+					Do(setResourceVersion("1")),
+				c.EXPECT().Delete(ctx, undesired),
+			)
+
+			res, err := ReconcileChildren(ctx, c, owner, &corev1.ConfigMapList{},
+				[]client.Object{desiredUnchanged, desiredChanged, desiredNew},
+				fieldManager,
+				client.InNamespace(corev1.NamespaceDefault),
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res).To(Equal(ReconcileChildrenResult{
+				Created:     NewObjectRefSet(configMapRef("new")),
+				Patched:     NewObjectRefSet(configMapRef("changed")),
+				Unchanged:   NewObjectRefSet(configMapRef("unchanged")),
+				Deleted:     NewObjectRefSet(configMapRef("undesired")),
+				Conflicting: NewObjectRefSet(),
+			}))
+		})
+
+		It("should not adopt objects that are uncontrolled or controlled by another owner", func() {
+			otherOwner := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: "other-owner", UID: "other-owner-uid"}}
+			foreign := newConfigMap("foreign", "1", false)
+			Expect(controllerutil.SetControllerReference(otherOwner, foreign, scheme.Scheme)).To(Succeed())
+			uncontrolled := newConfigMap("uncontrolled", "1", false)
+
+			desiredForeign := newConfigMap("foreign", "", false)
+			desiredUncontrolled := newConfigMap("uncontrolled", "", false)
+
+			returnObject := func(existing *corev1.ConfigMap) func(context.Context, client.ObjectKey, client.Object, ...client.GetOption) {
+				return func(_ context.Context, _ client.ObjectKey, obj client.Object, _ ...client.GetOption) {
+					*obj.(*corev1.ConfigMap) = *existing
+				}
+			}
+
+			gomock.InOrder(
+				c.EXPECT().List(ctx, gomock.AssignableToTypeOf(&corev1.ConfigMapList{})).Do(
+					func(_ context.Context, list client.ObjectList, _ ...client.ListOption) {
+						list.(*corev1.ConfigMapList).Items = []corev1.ConfigMap{*foreign, *uncontrolled}
+					}),
+				c.EXPECT().Get(ctx, client.ObjectKeyFromObject(desiredForeign), gomock.Any()).Do(returnObject(foreign)),
+				c.EXPECT().Get(ctx, client.ObjectKeyFromObject(desiredUncontrolled), gomock.Any()).Do(returnObject(uncontrolled)),
+			)
+
+			res, err := ReconcileChildren(ctx, c, owner, &corev1.ConfigMapList{},
+				[]client.Object{desiredForeign, desiredUncontrolled},
+				fieldManager,
+			)
+			Expect(err).To(MatchError(ContainSubstring("refusing to adopt objects not controlled by owner")))
+			Expect(res.Conflicting).To(Equal(NewObjectRefSet(configMapRef("foreign"), configMapRef("uncontrolled"))))
+			Expect(res.Created.Len()).To(BeZero())
+			Expect(desiredForeign.OwnerReferences).To(BeEmpty())
+		})
+
+		It("should return the result up to a failing apply", func() {
+			existing := newConfigMap("existing", "1", true)
+			desiredExisting := newConfigMap("existing", "", false)
+			desiredFailing := newConfigMap("failing", "", false)
+
+			gomock.InOrder(
+				c.EXPECT().List(ctx, gomock.Any()).Do(
+					func(_ context.Context, list client.ObjectList, _ ...client.ListOption) {
+						list.(*corev1.ConfigMapList).Items = []corev1.ConfigMap{*existing}
+					}),
+				c.EXPECT().Patch(ctx, desiredExisting, client.Apply, client.FieldOwner(fieldManager), client.ForceOwnership).
+					Do(func(_ context.Context, obj client.Object, _ client.Patch, _ ...client.PatchOption) {
+						obj.SetResourceVersion("1")
+					}),
+				c.EXPECT().Get(ctx, client.ObjectKeyFromObject(desiredFailing), gomock.Any()).
+					Return(apierrors.NewNotFound(corev1.Resource("configmaps"), desiredFailing.Name)),
+				c.EXPECT().Patch(ctx, desiredFailing, client.Apply, client.FieldOwner(fieldManager)).
+					Return(fmt.Errorf("some error")),
+			)
+
+			res, err := ReconcileChildren(ctx, c, owner, &corev1.ConfigMapList{},
+				[]client.Object{desiredExisting, desiredFailing},
+				fieldManager,
+			)
+			Expect(err).To(MatchError(ContainSubstring("error applying ConfigMap default/failing: some error")))
+			Expect(res.Unchanged).To(Equal(NewObjectRefSet(configMapRef("existing"))))
+			Expect(res.Created.Len()).To(BeZero())
+		})
+
+		It("should return an error if deleting an undesired child fails", func() {
+			undesired := newConfigMap("undesired", "1", true)
+
+			gomock.InOrder(
+				c.EXPECT().List(ctx, gomock.Any()).Do(
+					func(_ context.Context, list client.ObjectList, _ ...client.ListOption) {
+						list.(*corev1.ConfigMapList).Items = []corev1.ConfigMap{*undesired}
+					}),
+				c.EXPECT().Delete(ctx, undesired).Return(fmt.Errorf("some error")),
+			)
+
+			res, err := ReconcileChildren(ctx, c, owner, &corev1.ConfigMapList{}, nil, fieldManager)
+			Expect(err).To(MatchError(ContainSubstring("error deleting ConfigMap default/undesired: some error")))
+			Expect(res.Deleted.Len()).To(BeZero())
+		})
+	})
+})
@@ -18,6 +18,7 @@ package clientutils
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"reflect"
 
@@ -69,6 +70,7 @@ func IgnoreAlreadyExists(err error) error {
 
 // CreateMultipleFromFile creates multiple objects by reading the given file as unstructured objects and then creating
 // the read objects using the given client and options.
+// To create the objects in dependency order, specify OrderByKind.
 func CreateMultipleFromFile(ctx context.Context, c client.Client, filename string, opts ...client.CreateOption) ([]unstructured.Unstructured, error) {
 	objs, err := unstructuredutils.ReadFile(filename)
 	if err != nil {
@@ -83,11 +85,34 @@ func CreateMultipleFromFile(ctx context.Context, c client.Client, filename strin
 }
 
 // CreateMultiple creates multiple objects using the given client and options.
+// By default, it aborts on the first error. If ContinueOnError is specified, all objects are processed
+// and any failures are reported as *MultipleError.
+// If OrderByKind is specified, the objects are created in the order of the priority of their kind.
+// If Parallelism is specified, objects of the same priority are created in parallel.
 func CreateMultiple(ctx context.Context, c client.Client, objs []client.Object, opts ...client.CreateOption) error {
-	for _, obj := range objs {
-		if err := c.Create(ctx, obj, opts...); err != nil {
+	o, opts := splitMultipleOptions(opts)
+	create := func(i int) error {
+		return c.Create(ctx, objs[i], opts...)
+	}
+	if o.ContinueOnError {
+		results, err := runMultiple(ctx, c, o, objs, false, true, sequentialRunFunc(o), create)
+		if err != nil {
+			return err
+		}
+		return aggregateResults(c, "creating", results)
+	}
+
+	tiers, err := processingTiers(c, o, objs, false)
+	if err != nil {
+		return err
+	}
+	for _, tier := range tiers {
+		if i, err := runTier(ctx, o, objs, tier, create); err != nil {
 			return fmt.Errorf("error creating object %s: %w",
-				client.ObjectKeyFromObject(obj), err)
+				client.ObjectKeyFromObject(objs[i]), err)
+		}
+		if err := waitForEstablishedInTier(ctx, c, o, objs, tier); err != nil {
+			return err
 		}
 	}
 	return nil
@@ -132,14 +157,30 @@ func ObjectsFromGetRequests(reqs []GetRequest) []client.Object {
 	return objs
 }
 
-type getRequestTypedKey struct {
+// getRequestKey is the identity of a GetRequest in a GetRequestSet.
+// For typed objects, typ is set, for unstructured objects, gvk is set.
+type getRequestKey struct {
 	typ       reflect.Type
+	gvk       schema.GroupVersionKind
 	objectKey client.ObjectKey
 }
 
-type getRequestUnstructuredKey struct {
-	gvk       schema.GroupVersionKind
-	objectKey client.ObjectKey
+func (k getRequestKey) typeSortKey() string {
+	if k.typ != nil {
+		return "typed:" + k.typ.PkgPath() + "." + k.typ.Name()
+	}
+	return "unstructured:" + k.gvk.String()
+}
+
+func lessGetRequestKey(a, b getRequestKey) bool {
+	if a.objectKey != b.objectKey {
+		return lessObjectKey(a.objectKey, b.objectKey)
+	}
+	return a.typeSortKey() < b.typeSortKey()
+}
+
+func getRequestFromKey(k getRequestKey, obj client.Object) GetRequest {
+	return GetRequest{Key: k.objectKey, Object: obj}
 }
 
 // GetRequestSet is a set of GetRequest.
@@ -150,19 +191,17 @@ type getRequestUnstructuredKey struct {
 // client.ObjectKey is used as identity.
 // If a typed object is *not* a pointer to a struct, a panic will happen.
 type GetRequestSet struct {
-	typed        map[getRequestTypedKey]client.Object
-	unstructured map[getRequestUnstructuredKey]client.Object
+	items map[getRequestKey]client.Object
 }
 
-func (s *GetRequestSet) unstructuredKey(req GetRequest) getRequestUnstructuredKey {
-	u := req.Object.(*unstructured.Unstructured)
-	return getRequestUnstructuredKey{
-		gvk:       u.GroupVersionKind(),
-		objectKey: req.Key,
+func (s *GetRequestSet) key(req GetRequest) getRequestKey {
+	if u, ok := req.Object.(*unstructured.Unstructured); ok {
+		return getRequestKey{
+			gvk:       u.GroupVersionKind(),
+			objectKey: req.Key,
+		}
 	}
-}
 
-func (s *GetRequestSet) typedKey(req GetRequest) getRequestTypedKey {
 	t := reflect.TypeOf(req.Object)
 	// Taken from runtime.Scheme.AddKnownTypes.
 	// In this case it's fine to panic as we distinguish between typed and unstructured
@@ -174,7 +213,7 @@ func (s *GetRequestSet) typedKey(req GetRequest) getRequestTypedKey {
 	if t.Kind() != reflect.Struct {
 		panic("All types must be pointers to struct")
 	}
-	return getRequestTypedKey{
+	return getRequestKey{
 		typ:       t,
 		objectKey: req.Key,
 	}
@@ -183,67 +222,135 @@ func (s *GetRequestSet) typedKey(req GetRequest) getRequestTypedKey {
 // Insert inserts the given items into the set.
 func (s *GetRequestSet) Insert(items ...GetRequest) {
 	for _, item := range items {
-		switch item.Object.(type) {
-		case *unstructured.Unstructured:
-			s.unstructured[s.unstructuredKey(item)] = item.Object
-		default:
-			s.typed[s.typedKey(item)] = item.Object
-		}
+		s.items[s.key(item)] = item.Object
 	}
 }
 
 // Len returns the length of the set.
 func (s *GetRequestSet) Len() int {
-	return len(s.typed) + len(s.unstructured)
+	return len(s.items)
 }
 
 // Has checks if the given item is present in the set.
 func (s *GetRequestSet) Has(item GetRequest) bool {
-	var ok bool
-	switch item.Object.(type) {
-	case *unstructured.Unstructured:
-		_, ok = s.unstructured[s.unstructuredKey(item)]
-	default:
-		_, ok = s.typed[s.typedKey(item)]
-	}
+	_, ok := s.items[s.key(item)]
 	return ok
 }
 
 // Delete deletes the given items from the set, if they were present.
 func (s *GetRequestSet) Delete(items ...GetRequest) {
 	for _, item := range items {
-		switch item.Object.(type) {
-		case *unstructured.Unstructured:
-			delete(s.unstructured, s.unstructuredKey(item))
-		default:
-			delete(s.typed, s.typedKey(item))
-		}
+		delete(s.items, s.key(item))
 	}
 }
 
 // Iterate iterates through the get requests of this set using the given function.
 // If the function returns true (i.e. stop), the iteration is canceled.
 func (s *GetRequestSet) Iterate(f func(GetRequest) (cont bool)) {
-	for k, v := range s.typed {
-		if cont := f(GetRequest{Key: k.objectKey, Object: v}); !cont {
+	for k, v := range s.items {
+		if cont := f(getRequestFromKey(k, v)); !cont {
 			return
 		}
 	}
-	for k, v := range s.unstructured {
-		if cont := f(GetRequest{Key: k.objectKey, Object: v}); !cont {
-			return
+}
+
+// UnsortedList returns all GetRequests of this set in arbitrary order.
+func (s *GetRequestSet) UnsortedList() []GetRequest {
+	return setUnsortedList(s.items, getRequestFromKey)
+}
+
+// List returns all GetRequests of this set, sorted by namespace, name and type.
+func (s *GetRequestSet) List() []GetRequest {
+	return setList(s.items, lessGetRequestKey, getRequestFromKey)
+}
+
+// Clone returns a copy of the set. The objects of the GetRequests are not copied.
+func (s *GetRequestSet) Clone() *GetRequestSet {
+	return &GetRequestSet{items: setClone(s.items)}
+}
+
+// Union returns a new set containing the items of both sets.
+// For items present in both sets, the GetRequest of s is used.
+func (s *GetRequestSet) Union(s2 *GetRequestSet) *GetRequestSet {
+	return &GetRequestSet{items: setUnion(s.items, s2.items)}
+}
+
+// Intersection returns a new set containing the items of s that are also present in s2.
+func (s *GetRequestSet) Intersection(s2 *GetRequestSet) *GetRequestSet {
+	return &GetRequestSet{items: setIntersection(s.items, s2.items)}
+}
+
+// Difference returns a new set containing the items of s that are not in s2.
+func (s *GetRequestSet) Difference(s2 *GetRequestSet) *GetRequestSet {
+	return &GetRequestSet{items: setDifference(s.items, s2.items)}
+}
+
+// SymmetricDifference returns a new set containing the items that are in exactly one of the sets.
+func (s *GetRequestSet) SymmetricDifference(s2 *GetRequestSet) *GetRequestSet {
+	return &GetRequestSet{items: setSymmetricDifference(s.items, s2.items)}
+}
+
+// IsSuperset reports whether s contains all items of s2.
+func (s *GetRequestSet) IsSuperset(s2 *GetRequestSet) bool {
+	return setIsSuperset(s.items, s2.items)
+}
+
+// Equal reports whether both sets contain the same items.
+func (s *GetRequestSet) Equal(s2 *GetRequestSet) bool {
+	return setEqual(s.items, s2.items)
+}
+
+// getRequestEntry is the serialized form of a GetRequest.
+type getRequestEntry struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+}
+
+// MarshalJSON implements json.Marshaler.
+//
+// The set is encoded as a sorted list of objects with apiVersion, kind, namespace and name.
+// The objects of all GetRequests have to have their group version kind set.
+func (s *GetRequestSet) MarshalJSON() ([]byte, error) {
+	reqs := s.List()
+	entries := make([]getRequestEntry, 0, len(reqs))
+	for _, req := range reqs {
+		gvk := req.Object.GetObjectKind().GroupVersionKind()
+		if gvk.Empty() {
+			return nil, fmt.Errorf("object %T %s has no group version kind set", req.Object, req.Key)
 		}
+
+		apiVersion, kind := gvk.ToAPIVersionAndKind()
+		entries = append(entries, getRequestEntry{
+			APIVersion: apiVersion,
+			Kind:       kind,
+			Namespace:  req.Key.Namespace,
+			Name:       req.Key.Name,
+		})
 	}
+	return json.Marshal(entries)
 }
 
-// List returns all GetRequests of this set.
-func (s *GetRequestSet) List() []GetRequest {
-	res := make([]GetRequest, 0, s.Len())
-	s.Iterate(func(request GetRequest) (cont bool) {
-		res = append(res, request)
-		return true
-	})
-	return res
+// UnmarshalJSON implements json.Unmarshaler.
+// The objects of the decoded GetRequests are *unstructured.Unstructured.
+func (s *GetRequestSet) UnmarshalJSON(data []byte) error {
+	var entries []getRequestEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+
+	res := NewGetRequestSet()
+	for _, entry := range entries {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion(entry.APIVersion)
+		obj.SetKind(entry.Kind)
+		obj.SetNamespace(entry.Namespace)
+		obj.SetName(entry.Name)
+		res.Insert(GetRequestFromObject(obj))
+	}
+	*s = *res
+	return nil
 }
 
 // NewGetRequestSet creates a new set of GetRequest.
@@ -255,8 +362,7 @@ func (s *GetRequestSet) List() []GetRequest {
 // If a typed object is *not* a pointer to a struct, a panic will happen.
 func NewGetRequestSet(items ...GetRequest) *GetRequestSet {
 	s := &GetRequestSet{
-		typed:        make(map[getRequestTypedKey]client.Object),
-		unstructured: make(map[getRequestUnstructuredKey]client.Object),
+		items: make(map[getRequestKey]client.Object),
 	}
 	s.Insert(items...)
 	return s
@@ -352,20 +458,45 @@ func ObjectsFromPatchRequests(reqs []PatchRequest) []client.Object {
 }
 
 // PatchMultiple executes multiple PatchRequest with the given client.PatchOption.
+// By default, it aborts on the first error. If ContinueOnError is specified, all requests are processed
+// and any failures are reported as *MultipleError.
+// If OrderByKind is specified, the objects are patched in the order of the priority of their kind.
+// If Parallelism is specified, objects of the same priority are patched in parallel.
 func PatchMultiple(ctx context.Context, c client.Client, reqs []PatchRequest, opts ...client.PatchOption) error {
-	for _, req := range reqs {
-		if err := c.Patch(ctx, req.Object, req.Patch, opts...); err != nil {
+	o, opts := splitMultipleOptions(opts)
+	objs := ObjectsFromPatchRequests(reqs)
+	patch := func(i int) error {
+		return c.Patch(ctx, reqs[i].Object, reqs[i].Patch, opts...)
+	}
+	if o.ContinueOnError {
+		results, err := runMultiple(ctx, c, o, objs, false, true, sequentialRunFunc(o), patch)
+		if err != nil {
+			return err
+		}
+		return aggregateResults(c, "patching", results)
+	}
+
+	tiers, err := processingTiers(c, o, objs, false)
+	if err != nil {
+		return err
+	}
+	for _, tier := range tiers {
+		if i, err := runTier(ctx, o, objs, tier, patch); err != nil {
 			return fmt.Errorf("error patching object %s: %w",
-				client.ObjectKeyFromObject(req.Object),
+				client.ObjectKeyFromObject(objs[i]),
 				err,
 			)
 		}
+		if err := waitForEstablishedInTier(ctx, c, o, objs, tier); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
 // PatchMultipleFromFile patches all objects from the given filename using the patchFor function.
 // The returned unstructured.Unstructured objects contain the result of applying them.
+// To patch the objects in dependency order, specify OrderByKind.
 func PatchMultipleFromFile(
 	ctx context.Context,
 	c client.Client,
@@ -393,6 +524,7 @@ func PatchMultipleFromFile(
 
 // DeleteMultipleFromFile deletes all client.Object objects from the given file with the given
 // client.DeleteOption options.
+// To delete the objects in reverse dependency order, specify OrderByKind.
 func DeleteMultipleFromFile(ctx context.Context, c client.Client, filename string, opts ...client.DeleteOption) error {
 	us, err := unstructuredutils.ReadFile(filename)
 	if err != nil {
@@ -404,11 +536,31 @@ func DeleteMultipleFromFile(ctx context.Context, c client.Client, filename strin
 }
 
 // DeleteMultiple deletes multiple given client.Object objects using the given client.DeleteOption options.
+// By default, it aborts on the first error. If ContinueOnError is specified, all objects are processed
+// and any failures are reported as *MultipleError.
+// If OrderByKind is specified, the objects are deleted in the reverse order of the priority of their kind.
+// If Parallelism is specified, objects of the same priority are deleted in parallel.
 func DeleteMultiple(ctx context.Context, c client.Client, objs []client.Object, opts ...client.DeleteOption) error {
-	for _, obj := range objs {
-		if err := c.Delete(ctx, obj, opts...); err != nil {
+	o, opts := splitMultipleOptions(opts)
+	del := func(i int) error {
+		return c.Delete(ctx, objs[i], opts...)
+	}
+	if o.ContinueOnError {
+		results, err := runMultiple(ctx, c, o, objs, true, false, sequentialRunFunc(o), del)
+		if err != nil {
+			return err
+		}
+		return aggregateResults(c, "deleting", results)
+	}
+
+	tiers, err := processingTiers(c, o, objs, true)
+	if err != nil {
+		return err
+	}
+	for _, tier := range tiers {
+		if i, err := runTier(ctx, o, objs, tier, del); err != nil {
 			return fmt.Errorf("error deleting object %s: %w",
-				client.ObjectKeyFromObject(obj),
+				client.ObjectKeyFromObject(objs[i]),
 				err,
 			)
 		}
@@ -487,6 +639,8 @@ func IsOlderThan(obj client.Object) func(other client.Object) (bool, error) {
 // If multiple objects match, the winning object is the oldest.
 // If no object matches, initFunc is called and the new object is created.
 // mutateFunc is optional, if none is specified no mutation will happen.
+//
+// For a type-safe variant whose functions receive the objects as arguments, see TypedCreateOrUseAndPatch.
 func CreateOrUseAndPatch(
 	ctx context.Context,
 	c client.Client,
@@ -16,6 +16,7 @@ package clientutils_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
@@ -344,6 +345,63 @@ var _ = Describe("Clientutils", func() {
 				s := NewGetRequestSet(GetRequestFromObject(cm), GetRequestFromObject(uPod))
 				Expect(s.List()).To(ConsistOf(GetRequestFromObject(cm), GetRequestFromObject(uPod)))
 			})
+
+			It("should sort the entries by namespace, name and type", func() {
+				s := NewGetRequestSet(GetRequestFromObject(secret), GetRequestFromObject(uPod), GetRequestFromObject(cm))
+				Expect(s.List()).To(Equal([]GetRequest{
+					GetRequestFromObject(cm),
+					GetRequestFromObject(uPod),
+					GetRequestFromObject(secret),
+				}))
+			})
+		})
+
+		Describe("UnsortedList", func() {
+			It("should contain all entries as a list", func() {
+				s := NewGetRequestSet(GetRequestFromObject(cm), GetRequestFromObject(uPod))
+				Expect(s.UnsortedList()).To(ConsistOf(GetRequestFromObject(cm), GetRequestFromObject(uPod)))
+			})
+		})
+
+		Describe("Set algebra", func() {
+			It("should compute union, intersection and differences", func() {
+				s1 := NewGetRequestSet(GetRequestFromObject(cm), GetRequestFromObject(uPod))
+				s2 := NewGetRequestSet(GetRequestFromObject(uPod), GetRequestFromObject(secret))
+
+				Expect(s1.Union(s2).List()).To(ConsistOf(
+					GetRequestFromObject(cm), GetRequestFromObject(uPod), GetRequestFromObject(secret),
+				))
+				Expect(s1.Intersection(s2).List()).To(ConsistOf(GetRequestFromObject(uPod)))
+				Expect(s1.Difference(s2).List()).To(ConsistOf(GetRequestFromObject(cm)))
+				Expect(s1.SymmetricDifference(s2).List()).To(ConsistOf(GetRequestFromObject(cm), GetRequestFromObject(secret)))
+				Expect(s1.Len()).To(Equal(2))
+				Expect(s2.Len()).To(Equal(2))
+			})
+
+			It("should compare sets", func() {
+				s1 := NewGetRequestSet(GetRequestFromObject(cm), GetRequestFromObject(uPod))
+				Expect(s1.IsSuperset(NewGetRequestSet(GetRequestFromObject(uPod)))).To(BeTrue())
+				Expect(s1.IsSuperset(NewGetRequestSet(GetRequestFromObject(secret)))).To(BeFalse())
+				Expect(s1.Equal(NewGetRequestSet(GetRequestFromObject(uPod), GetRequestFromObject(cm)))).To(BeTrue())
+				Expect(s1.Equal(NewGetRequestSet(GetRequestFromObject(cm)))).To(BeFalse())
+			})
+		})
+
+		Describe("JSON", func() {
+			It("should marshal and unmarshal the set", func() {
+				data, err := json.Marshal(NewGetRequestSet(GetRequestFromObject(uPod)))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(data).To(MatchJSON(`[{"apiVersion":"v1","kind":"Pod","namespace":"default","name":"my-pod"}]`))
+
+				s := NewGetRequestSet()
+				Expect(json.Unmarshal(data, s)).To(Succeed())
+				Expect(s.List()).To(Equal([]GetRequest{GetRequestFromObject(uPod)}))
+			})
+
+			It("should error marshalling objects without group version kind", func() {
+				_, err := json.Marshal(NewGetRequestSet(GetRequestFromObject(cm)))
+				Expect(err).To(HaveOccurred())
+			})
 		})
 	})
 
@@ -487,6 +545,39 @@ var _ = Describe("Clientutils", func() {
 			Expect(errors.Is(err, someErr)).To(BeTrue())
 		})
 
+		It("should continue on error if requested and report all failed objects", func() {
+			reqs := []PatchRequest{
+				{
+					Object: cm,
+					Patch:  client.Apply,
+				},
+				{
+					Object: secret,
+					Patch:  client.Apply,
+				},
+			}
+			someErr := fmt.Errorf("some error")
+			gomock.InOrder(
+				c.EXPECT().Patch(ctx, cm, client.Apply).Return(someErr),
+				c.EXPECT().Patch(ctx, secret, client.Apply),
+				c.EXPECT().Scheme().Return(scheme.Scheme),
+			)
+
+			err := PatchMultiple(ctx, c, reqs, ContinueOnError)
+			Expect(errors.Is(err, someErr)).To(BeTrue())
+
+			var multiErr *MultipleError
+			Expect(errors.As(err, &multiErr)).To(BeTrue())
+			Expect(multiErr.Errors).To(Equal([]ObjectError{
+				{
+					Ref:    ObjectRef{GroupKind: schema.GroupKind{Kind: "ConfigMap"}, Key: cmKey},
+					Object: cm,
+					Err:    someErr,
+				},
+			}))
+			Expect(multiErr.Succeeded).To(Equal([]client.Object{secret}))
+		})
+
 		It("should patch multiple objects", func() {
 			reqs := []PatchRequest{
 				{
@@ -551,6 +642,23 @@ var _ = Describe("Clientutils", func() {
 			Expect(errors.Is(err, someErr)).To(BeTrue())
 		})
 
+		It("should continue on error if requested and report all failed objects", func() {
+			notFoundErr := apierrors.NewNotFound(cmGR, cm.Name)
+			gomock.InOrder(
+				c.EXPECT().Delete(ctx, cm).Return(notFoundErr),
+				c.EXPECT().Delete(ctx, secret),
+				c.EXPECT().Scheme().Return(scheme.Scheme),
+			)
+
+			err := DeleteMultiple(ctx, c, []client.Object{cm, secret}, ContinueOnError)
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+			var multiErr *MultipleError
+			Expect(errors.As(err, &multiErr)).To(BeTrue())
+			Expect(multiErr.Failed()).To(Equal([]client.Object{cm}))
+			Expect(multiErr.Succeeded).To(Equal([]client.Object{secret}))
+		})
+
 		It("should delete multiple objects", func() {
 			gomock.InOrder(
 				c.EXPECT().Delete(ctx, cm),
@@ -0,0 +1,142 @@
+// Copyright 2023 IronCore authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientutils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	indexValueSeparator = ","
+	indexValueEscape    = `\`
+)
+
+var indexValueEscaper = strings.NewReplacer(
+	indexValueEscape, indexValueEscape+indexValueEscape,
+	indexValueSeparator, indexValueEscape+indexValueSeparator,
+)
+
+// EncodeIndexValues encodes the given values into a single index value.
+//
+// The values are escaped and joined with ',', so the encoding is stable and distinct values
+// always result in distinct index values.
+func EncodeIndexValues(values ...string) string {
+	escaped := make([]string, len(values))
+	for i, value := range values {
+		escaped[i] = indexValueEscaper.Replace(value)
+	}
+	return strings.Join(escaped, indexValueSeparator)
+}
+
+// SingleValue returns an index extractor that emits the single value returned by f.
+func SingleValue[T client.Object](f func(obj T) string) func(obj T) []string {
+	return func(obj T) []string {
+		return []string{f(obj)}
+	}
+}
+
+// MapKeys returns an index extractor that emits one value per key of the map returned by f.
+func MapKeys[T client.Object](f func(obj T) map[string]string) func(obj T) []string {
+	return func(obj T) []string {
+		return sets.List(sets.KeySet(f(obj)))
+	}
+}
+
+// MapEntries returns an index extractor that emits one value per entry of the map returned by f.
+// Use MapEntryValue to obtain the index value of an entry.
+func MapEntries[T client.Object](f func(obj T) map[string]string) func(obj T) []string {
+	return func(obj T) []string {
+		m := f(obj)
+		res := make([]string, 0, len(m))
+		for key, value := range m {
+			res = append(res, MapEntryValue(key, value))
+		}
+		sort.Strings(res)
+		return res
+	}
+}
+
+// MapEntryValue returns the index value of a map entry as emitted by MapEntries.
+func MapEntryValue(key, value string) string {
+	return EncodeIndexValues(key, value)
+}
+
+// CompositeFieldIndex is a FieldIndex whose values are composed of the values of multiple extractors.
+//
+// For each combination of the values emitted by the extractors, an index value is emitted using
+// EncodeIndexValues. If any extractor emits no value, the object is not indexed.
+type CompositeFieldIndex[T client.Object] struct {
+	FieldIndex[T]
+	numExtractors int
+}
+
+// NewCompositeFieldIndex declares a new CompositeFieldIndex with the given field name for objects of the type of
+// the given object. The index values are composed of the values of the given extractors, in order.
+func NewCompositeFieldIndex[T client.Object](obj T, field string, extractors ...func(obj T) []string) CompositeFieldIndex[T] {
+	return CompositeFieldIndex[T]{
+		FieldIndex: NewFieldIndex(obj, field, func(obj T) []string {
+			return compositeIndexValues(obj, extractors)
+		}),
+		numExtractors: len(extractors),
+	}
+}
+
+func compositeIndexValues[T client.Object](obj T, extractors []func(obj T) []string) []string {
+	if len(extractors) == 0 {
+		return nil
+	}
+
+	combinations := [][]string{nil}
+	for _, extract := range extractors {
+		values := sets.List(sets.New(extract(obj)...))
+		if len(values) == 0 {
+			return nil
+		}
+
+		next := make([][]string, 0, len(combinations)*len(values))
+		for _, combination := range combinations {
+			for _, value := range values {
+				next = append(next, append(combination[:len(combination):len(combination)], value))
+			}
+		}
+		combinations = next
+	}
+
+	res := make([]string, len(combinations))
+	for i, combination := range combinations {
+		res[i] = EncodeIndexValues(combination...)
+	}
+	return res
+}
+
+// Value returns the index value for the given values, one per extractor.
+// It panics if the number of values does not match the number of extractors.
+func (i CompositeFieldIndex[T]) Value(values ...string) string {
+	if len(values) != i.numExtractors {
+		panic(fmt.Sprintf("composite index %s: expected %d values but got %d", i.field, i.numExtractors, len(values)))
+	}
+	return EncodeIndexValues(values...)
+}
+
+// MatchingFields returns a client.MatchingFields list option that matches objects with the given values,
+// one per extractor. It panics if the number of values does not match the number of extractors.
+func (i CompositeFieldIndex[T]) MatchingFields(values ...string) client.MatchingFields {
+	return i.MatchingField(i.Value(values...))
+}
@@ -0,0 +1,116 @@
+// Copyright 2023 IronCore authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientutils_test
+
+import (
+	"context"
+
+	. "github.com/ironcore-dev/controller-utils/clientutils"
+	mockclient "github.com/ironcore-dev/controller-utils/mock/controller-runtime/client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("CompositeIndex", func() {
+	var (
+		nodeName = SingleValue(func(pod *corev1.Pod) string { return pod.Spec.NodeName })
+		phase    = SingleValue(func(pod *corev1.Pod) string { return string(pod.Status.Phase) })
+		labels   = func(pod *corev1.Pod) map[string]string { return pod.Labels }
+	)
+
+	Describe("EncodeIndexValues", func() {
+		It("should join the values", func() {
+			Expect(EncodeIndexValues("foo", "bar")).To(Equal("foo,bar"))
+		})
+
+		It("should escape separators so that distinct values do not collide", func() {
+			Expect(EncodeIndexValues("a,b", "c")).To(Equal(`a\,b,c`))
+			Expect(EncodeIndexValues("a", "b,c")).To(Equal(`a,b\,c`))
+			Expect(EncodeIndexValues(`a\`, "b")).NotTo(Equal(EncodeIndexValues("a", `\b`)))
+		})
+	})
+
+	Describe("MapKeys", func() {
+		It("should emit one value per key", func() {
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"b": "2", "a": "1"}}}
+			Expect(MapKeys(labels)(pod)).To(Equal([]string{"a", "b"}))
+		})
+	})
+
+	Describe("MapEntries", func() {
+		It("should emit one value per entry", func() {
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"b": "2", "a": "1"}}}
+			Expect(MapEntries(labels)(pod)).To(Equal([]string{MapEntryValue("a", "1"), MapEntryValue("b", "2")}))
+		})
+	})
+
+	Describe("CompositeFieldIndex", func() {
+		var (
+			index CompositeFieldIndex[*corev1.Pod]
+		)
+		BeforeEach(func() {
+			index = NewCompositeFieldIndex(&corev1.Pod{}, "spec.nodeName,status.phase", nodeName, phase)
+		})
+
+		It("should emit the composite value", func() {
+			pod := &corev1.Pod{
+				Spec:   corev1.PodSpec{NodeName: "my-node"},
+				Status: corev1.PodStatus{Phase: corev1.PodRunning},
+			}
+			Expect(index.IndexerFunc()(pod)).To(Equal([]string{"my-node,Running"}))
+		})
+
+		It("should emit all combinations of multiple values", func() {
+			index := NewCompositeFieldIndex(&corev1.Pod{}, "spec.nodeName,labels", nodeName, MapKeys(labels))
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"foo": "", "bar": ""}},
+				Spec:       corev1.PodSpec{NodeName: "my-node"},
+			}
+			Expect(index.IndexerFunc()(pod)).To(Equal([]string{"my-node,bar", "my-node,foo"}))
+		})
+
+		It("should not emit values if an extractor emits no value", func() {
+			index := NewCompositeFieldIndex(&corev1.Pod{}, "spec.nodeName,labels", nodeName, MapKeys(labels))
+			Expect(index.IndexerFunc()(&corev1.Pod{})).To(BeEmpty())
+		})
+
+		It("should create a list option matching the composite value", func() {
+			Expect(index.MatchingFields("my-node", "Running")).To(Equal(client.MatchingFields{
+				"spec.nodeName,status.phase": "my-node,Running",
+			}))
+		})
+
+		It("should panic if the number of values does not match", func() {
+			Expect(func() { index.MatchingFields("my-node") }).To(Panic())
+		})
+
+		It("should register and initialize the index", func() {
+			ctx := context.Background()
+			ctrl := gomock.NewController(GinkgoT())
+			fieldIndexer := mockclient.NewMockFieldIndexer(ctrl)
+			indexer := NewSharedFieldIndexer(fieldIndexer, scheme.Scheme)
+
+			fieldIndexer.EXPECT().IndexField(ctx, &corev1.Pod{}, "spec.nodeName,status.phase", gomock.Any())
+
+			Expect(index.Register(indexer)).To(Succeed())
+			Expect(index.IndexField(ctx, indexer)).To(Succeed())
+		})
+	})
+})
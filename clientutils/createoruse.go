@@ -0,0 +1,358 @@
+// Copyright 2023 IronCore authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientutils
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ironcore-dev/controller-utils/metautils"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// StaleLabel is the label duplicates are marked with when cleaning them up using DuplicateCleanupLabelStale.
+const StaleLabel = "controller-utils.ironcore.dev/stale"
+
+// DuplicateCleanupPolicy specifies how duplicates are cleaned up.
+type DuplicateCleanupPolicy string
+
+const (
+	// DuplicateCleanupNone leaves duplicates untouched.
+	DuplicateCleanupNone DuplicateCleanupPolicy = ""
+	// DuplicateCleanupDelete deletes duplicates.
+	DuplicateCleanupDelete DuplicateCleanupPolicy = "Delete"
+	// DuplicateCleanupOrphan removes the controller reference of the owner from duplicates.
+	DuplicateCleanupOrphan DuplicateCleanupPolicy = "Orphan"
+	// DuplicateCleanupLabelStale labels duplicates with StaleLabel.
+	DuplicateCleanupLabelStale DuplicateCleanupPolicy = "LabelStale"
+)
+
+// DuplicateCleanup specifies how to clean up duplicates.
+type DuplicateCleanup struct {
+	// Policy is the policy to apply to duplicates.
+	Policy DuplicateCleanupPolicy
+	// Owner restricts DuplicateCleanupDelete and DuplicateCleanupOrphan to duplicates controlled by it,
+	// as determined by metautils.IsControlledBy. All other duplicates are skipped.
+	// Owner is required for DuplicateCleanupDelete and DuplicateCleanupOrphan.
+	Owner client.Object
+	// DeleteOptions are the options used when deleting duplicates.
+	DeleteOptions []client.DeleteOption
+}
+
+// ApplyToCreateOrUseAndPatch implements CreateOrUseAndPatchOption.
+func (d DuplicateCleanup) ApplyToCreateOrUseAndPatch(o *CreateOrUseAndPatchOptions) {
+	o.DuplicateCleanup = d
+}
+
+// CleanupResult is the result of cleaning up duplicates.
+type CleanupResult struct {
+	// Deleted are the duplicates that were deleted or did not exist anymore.
+	Deleted []client.Object
+	// Orphaned are the duplicates whose controller reference was removed.
+	Orphaned []client.Object
+	// LabeledStale are the duplicates that were labeled with StaleLabel.
+	LabeledStale []client.Object
+	// Skipped are the duplicates that were left untouched.
+	Skipped []client.Object
+}
+
+func orphan(ctx context.Context, c client.Client, owner, obj client.Object) error {
+	base := obj.DeepCopyObject().(client.Object)
+	refs := obj.GetOwnerReferences()
+	filtered := make([]metav1.OwnerReference, 0, len(refs))
+	for _, ref := range refs {
+		if ref.Controller != nil && *ref.Controller && ref.UID == owner.GetUID() {
+			continue
+		}
+		filtered = append(filtered, ref)
+	}
+	obj.SetOwnerReferences(filtered)
+	return c.Patch(ctx, obj, client.MergeFrom(base))
+}
+
+func labelStale(ctx context.Context, c client.Client, obj client.Object) error {
+	base := obj.DeepCopyObject().(client.Object)
+	metautils.SetLabel(obj, StaleLabel, "true")
+	return c.Patch(ctx, obj, client.MergeFrom(base))
+}
+
+// CleanupDuplicates cleans up the given duplicates using the given DuplicateCleanup.
+// Orphaning and labeling duplicates patches them in place.
+// If an error occurs, the result up to the failing duplicate is returned alongside the error.
+func CleanupDuplicates(ctx context.Context, c client.Client, duplicates []client.Object, cleanup DuplicateCleanup) (CleanupResult, error) {
+	switch {
+	case cleanup.Policy == DuplicateCleanupDelete && cleanup.Owner == nil:
+		return CleanupResult{}, fmt.Errorf("must specify owner to delete duplicates")
+	case cleanup.Policy == DuplicateCleanupOrphan && cleanup.Owner == nil:
+		return CleanupResult{}, fmt.Errorf("must specify owner to orphan duplicates")
+	}
+	return cleanupDuplicates(ctx, c, duplicates, cleanup)
+}
+
+// cleanupDuplicates cleans up the given duplicates like CleanupDuplicates but does not require an owner.
+// Without an owner, DuplicateCleanupDelete deletes all duplicates.
+func cleanupDuplicates(ctx context.Context, c client.Client, duplicates []client.Object, cleanup DuplicateCleanup) (CleanupResult, error) {
+	var res CleanupResult
+	for _, duplicate := range duplicates {
+		if cleanup.Owner != nil && (cleanup.Policy == DuplicateCleanupDelete || cleanup.Policy == DuplicateCleanupOrphan) {
+			ok, err := metautils.IsControlledBy(c.Scheme(), cleanup.Owner, duplicate)
+			if err != nil {
+				return res, fmt.Errorf("error checking whether duplicate %s is controlled by owner: %w",
+					client.ObjectKeyFromObject(duplicate), err)
+			}
+			if !ok {
+				res.Skipped = append(res.Skipped, duplicate)
+				continue
+			}
+		}
+
+		switch cleanup.Policy {
+		case DuplicateCleanupNone:
+			res.Skipped = append(res.Skipped, duplicate)
+		case DuplicateCleanupDelete:
+			if _, err := DeleteIfExists(ctx, c, duplicate, cleanup.DeleteOptions...); err != nil {
+				return res, fmt.Errorf("error deleting duplicate %s: %w", client.ObjectKeyFromObject(duplicate), err)
+			}
+			res.Deleted = append(res.Deleted, duplicate)
+		case DuplicateCleanupOrphan:
+			if err := orphan(ctx, c, cleanup.Owner, duplicate); err != nil {
+				return res, fmt.Errorf("error orphaning duplicate %s: %w", client.ObjectKeyFromObject(duplicate), err)
+			}
+			res.Orphaned = append(res.Orphaned, duplicate)
+		case DuplicateCleanupLabelStale:
+			if err := labelStale(ctx, c, duplicate); err != nil {
+				return res, fmt.Errorf("error labeling duplicate %s as stale: %w", client.ObjectKeyFromObject(duplicate), err)
+			}
+			res.LabeledStale = append(res.LabeledStale, duplicate)
+		default:
+			return res, fmt.Errorf("unknown duplicate cleanup policy %q", cleanup.Policy)
+		}
+	}
+	return res, nil
+}
+
+// CreateOrUseAndPatchWithCleanup runs CreateOrUseAndPatch and cleans up all other objects using CleanupDuplicates.
+// The result of the cleanup is returned alongside the operation result.
+func CreateOrUseAndPatchWithCleanup(
+	ctx context.Context,
+	c client.Client,
+	objects []client.Object,
+	obj client.Object,
+	matchFunc func() (bool, error),
+	lessFunc func(other client.Object) (bool, error),
+	mutateFunc func() error,
+	cleanup DuplicateCleanup,
+) (controllerutil.OperationResult, CleanupResult, error) {
+	result, other, err := CreateOrUseAndPatch(ctx, c, objects, obj, matchFunc, lessFunc, mutateFunc)
+	if err != nil {
+		return result, CleanupResult{}, err
+	}
+
+	cleanupResult, err := CleanupDuplicates(ctx, c, other, cleanup)
+	if err != nil {
+		return result, cleanupResult, err
+	}
+	return result, cleanupResult, nil
+}
+
+// CreateOrUseAndPatchOptions are options for TypedCreateOrUseAndPatch.
+type CreateOrUseAndPatchOptions struct {
+	// DeleteDuplicates specifies whether all matching objects that did not win should be deleted,
+	// regardless of their controller.
+	DeleteDuplicates bool
+	// DeleteOptions are the options used when deleting duplicates.
+	DeleteOptions []client.DeleteOption
+	// DuplicateCleanup specifies how to clean up the duplicates. It must not be set alongside DeleteDuplicates.
+	DuplicateCleanup DuplicateCleanup
+}
+
+// ApplyToCreateOrUseAndPatch implements CreateOrUseAndPatchOption.
+func (o *CreateOrUseAndPatchOptions) ApplyToCreateOrUseAndPatch(o2 *CreateOrUseAndPatchOptions) {
+	if o.DeleteDuplicates {
+		o2.DeleteDuplicates = true
+	}
+	if o.DeleteOptions != nil {
+		o2.DeleteOptions = o.DeleteOptions
+	}
+	if o.DuplicateCleanup.Policy != DuplicateCleanupNone {
+		o2.DuplicateCleanup = o.DuplicateCleanup
+	}
+}
+
+// ApplyOptions applies all CreateOrUseAndPatchOption to this CreateOrUseAndPatchOptions.
+func (o *CreateOrUseAndPatchOptions) ApplyOptions(opts []CreateOrUseAndPatchOption) *CreateOrUseAndPatchOptions {
+	for _, opt := range opts {
+		opt.ApplyToCreateOrUseAndPatch(o)
+	}
+	return o
+}
+
+// CreateOrUseAndPatchOption is an option for TypedCreateOrUseAndPatch.
+type CreateOrUseAndPatchOption interface {
+	ApplyToCreateOrUseAndPatch(o *CreateOrUseAndPatchOptions)
+}
+
+// DeleteDuplicates instructs TypedCreateOrUseAndPatch to delete all matching objects that did not win,
+// using the given client.DeleteOption. Unlike a DuplicateCleanup with DuplicateCleanupDelete, it deletes
+// duplicates regardless of their controller.
+type DeleteDuplicates []client.DeleteOption
+
+// ApplyToCreateOrUseAndPatch implements CreateOrUseAndPatchOption.
+func (d DeleteDuplicates) ApplyToCreateOrUseAndPatch(o *CreateOrUseAndPatchOptions) {
+	o.DeleteDuplicates = true
+	o.DeleteOptions = d
+}
+
+// OlderFirst is a less function for TypedCreateOrUseAndPatch that prefers older objects over newer ones.
+func OlderFirst[T client.Object](a, b T) (bool, error) {
+	return a.GetCreationTimestamp().Time.Before(b.GetCreationTimestamp().Time), nil
+}
+
+// TypedCreateOrUseAndPatch traverses through a slice of objects and tries to find a matching object using match.
+//
+// If multiple objects match, the winner is determined using less, which reports whether a is preferred over b.
+// If less is nil, OlderFirst is used. The matching objects that did not win are the duplicates.
+// A copy of the winner is mutated using mutate and patched, if the mutation changed it semantically.
+// If no object matches, a copy of obj is mutated and created.
+// mutate is optional, if none is specified no mutation will happen.
+//
+// The given objects are not modified. The resulting object, the operation result and the duplicates are returned.
+// If DeleteDuplicates or a DuplicateCleanup is specified, copies of the duplicates are cleaned up using
+// CleanupDuplicates and only the copies that were not deleted are returned. Specifying both errors.
+func TypedCreateOrUseAndPatch[T client.Object](
+	ctx context.Context,
+	c client.Client,
+	objects []T,
+	obj T,
+	match func(obj T) (bool, error),
+	less func(a, b T) (bool, error),
+	mutate func(obj T) error,
+	opts ...CreateOrUseAndPatchOption,
+) (T, controllerutil.OperationResult, []T, error) {
+	o := (&CreateOrUseAndPatchOptions{}).ApplyOptions(opts)
+	if o.DeleteDuplicates && o.DuplicateCleanup.Policy != DuplicateCleanupNone {
+		var zero T
+		return zero, controllerutil.OperationResultNone, nil, fmt.Errorf("must not specify both DeleteDuplicates and DuplicateCleanup")
+	}
+	if less == nil {
+		less = OlderFirst[T]
+	}
+
+	var (
+		zero       T
+		best       T
+		found      bool
+		duplicates []T
+	)
+	for _, object := range objects {
+		ok, err := match(object)
+		if err != nil {
+			return zero, controllerutil.OperationResultNone, nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		if !found {
+			best, found = object, true
+			continue
+		}
+
+		isLess, err := less(object, best)
+		if err != nil {
+			return zero, controllerutil.OperationResultNone, nil, err
+		}
+		if isLess {
+			duplicates = append(duplicates, best)
+			best = object
+			continue
+		}
+		duplicates = append(duplicates, object)
+	}
+
+	var (
+		res    T
+		result controllerutil.OperationResult
+	)
+	if found {
+		res = best.DeepCopyObject().(T)
+		base := best.DeepCopyObject().(T)
+		if mutate != nil {
+			if err := mutate(res); err != nil {
+				return zero, controllerutil.OperationResultNone, nil, err
+			}
+		}
+
+		result = controllerutil.OperationResultNone
+		if !equality.Semantic.DeepEqual(base, res) {
+			if err := c.Patch(ctx, res, client.MergeFrom(base)); err != nil {
+				return zero, controllerutil.OperationResultNone, nil, err
+			}
+			result = controllerutil.OperationResultUpdated
+		}
+	} else {
+		res = obj.DeepCopyObject().(T)
+		if mutate != nil {
+			if err := mutate(res); err != nil {
+				return zero, controllerutil.OperationResultNone, nil, err
+			}
+		}
+
+		if err := c.Create(ctx, res); err != nil {
+			return zero, controllerutil.OperationResultNone, nil, err
+		}
+		result = controllerutil.OperationResultCreated
+	}
+
+	if !o.DeleteDuplicates && o.DuplicateCleanup.Policy == DuplicateCleanupNone {
+		return res, result, duplicates, nil
+	}
+
+	copies := make([]T, 0, len(duplicates))
+	objs := make([]client.Object, 0, len(duplicates))
+	for _, duplicate := range duplicates {
+		duplicateCopy := duplicate.DeepCopyObject().(T)
+		copies = append(copies, duplicateCopy)
+		objs = append(objs, duplicateCopy)
+	}
+
+	var (
+		cleanupResult CleanupResult
+		err           error
+	)
+	if o.DeleteDuplicates {
+		cleanupResult, err = cleanupDuplicates(ctx, c, objs, DuplicateCleanup{
+			Policy:        DuplicateCleanupDelete,
+			DeleteOptions: o.DeleteOptions,
+		})
+	} else {
+		cleanupResult, err = CleanupDuplicates(ctx, c, objs, o.DuplicateCleanup)
+	}
+
+	deleted := make(map[client.Object]struct{}, len(cleanupResult.Deleted))
+	for _, obj := range cleanupResult.Deleted {
+		deleted[obj] = struct{}{}
+	}
+	var remaining []T
+	for _, duplicateCopy := range copies {
+		if _, ok := deleted[duplicateCopy]; !ok {
+			remaining = append(remaining, duplicateCopy)
+		}
+	}
+	return res, result, remaining, err
+}
@@ -0,0 +1,302 @@
+// Copyright 2023 IronCore authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientutils_test
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/ironcore-dev/controller-utils/clientutils"
+	mockclient "github.com/ironcore-dev/controller-utils/mock/controller-runtime/client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+var _ = Describe("CreateOrUse", func() {
+	var (
+		ctx  context.Context
+		ctrl *gomock.Controller
+
+		c *mockclient.MockClient
+
+		cm1, cm2, cm3 *corev1.ConfigMap
+	)
+	BeforeEach(func() {
+		ctx = context.Background()
+		ctrl = gomock.NewController(GinkgoT())
+
+		c = mockclient.NewMockClient(ctrl)
+		c.EXPECT().Scheme().Return(scheme.Scheme).AnyTimes()
+
+		cm1 = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				CreationTimestamp: metav1.Unix(200, 0),
+				Namespace:         "foo",
+				Name:              "n1",
+			},
+		}
+		cm2 = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				CreationTimestamp: metav1.Unix(100, 0),
+				Namespace:         "foo",
+				Name:              "n2",
+			},
+		}
+		cm3 = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				CreationTimestamp: metav1.Unix(300, 0),
+				Namespace:         "foo",
+				Name:              "n3",
+			},
+		}
+	})
+
+	matchNames := func(names ...string) func(*corev1.ConfigMap) (bool, error) {
+		return func(cm *corev1.ConfigMap) (bool, error) {
+			for _, name := range names {
+				if cm.Name == name {
+					return true, nil
+				}
+			}
+			return false, nil
+		}
+	}
+
+	Describe("TypedCreateOrUseAndPatch", func() {
+		It("should use the oldest matching object and patch it when it's mutated", func() {
+			c.EXPECT().Patch(ctx, gomock.AssignableToTypeOf(&corev1.ConfigMap{}), gomock.Any()).Do(
+				func(_ context.Context, obj client.Object, patch client.Patch, _ ...client.PatchOption) {
+					data, err := patch.Data(obj)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(string(data)).To(Equal(`{"data":{"foo":"bar"}}`))
+				})
+
+			res, result, duplicates, err := TypedCreateOrUseAndPatch(ctx, c, []*corev1.ConfigMap{cm1, cm2, cm3}, &corev1.ConfigMap{},
+				matchNames("n1", "n2"),
+				nil,
+				func(cm *corev1.ConfigMap) error {
+					cm.Data = map[string]string{"foo": "bar"}
+					return nil
+				},
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(controllerutil.OperationResultUpdated))
+			Expect(res.Name).To(Equal("n2"))
+			Expect(res.Data).To(Equal(map[string]string{"foo": "bar"}))
+			Expect(duplicates).To(Equal([]*corev1.ConfigMap{cm1}))
+			Expect(cm2.Data).To(BeNil(), "input objects should not be modified")
+		})
+
+		It("should use a matching object without patching it if the mutation does not change it", func() {
+			res, result, duplicates, err := TypedCreateOrUseAndPatch(ctx, c, []*corev1.ConfigMap{cm1, cm2, cm3}, &corev1.ConfigMap{},
+				matchNames("n3"),
+				nil,
+				nil,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(controllerutil.OperationResultNone))
+			Expect(res).To(Equal(cm3))
+			Expect(duplicates).To(BeEmpty())
+		})
+
+		It("should use the custom less function to determine the winner", func() {
+			res, _, duplicates, err := TypedCreateOrUseAndPatch(ctx, c, []*corev1.ConfigMap{cm1, cm2, cm3}, &corev1.ConfigMap{},
+				matchNames("n1", "n2", "n3"),
+				func(a, b *corev1.ConfigMap) (bool, error) { return a.Name > b.Name, nil },
+				nil,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.Name).To(Equal("n3"))
+			Expect(duplicates).To(Equal([]*corev1.ConfigMap{cm1, cm2}))
+		})
+
+		It("should create a new object if none matches", func() {
+			c.EXPECT().Create(ctx, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "n4"}})
+
+			obj := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "foo"}}
+			res, result, duplicates, err := TypedCreateOrUseAndPatch(ctx, c, []*corev1.ConfigMap{cm1, cm2, cm3}, obj,
+				matchNames(),
+				nil,
+				func(cm *corev1.ConfigMap) error {
+					cm.Name = "n4"
+					return nil
+				},
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(controllerutil.OperationResultCreated))
+			Expect(res.Name).To(Equal("n4"))
+			Expect(duplicates).To(BeEmpty())
+			Expect(obj.Name).To(BeEmpty(), "template object should not be modified")
+		})
+
+		It("should delete the duplicates if specified", func() {
+			gomock.InOrder(
+				c.EXPECT().Delete(ctx, cm1, client.PropagationPolicy(metav1.DeletePropagationForeground)),
+				c.EXPECT().Delete(ctx, cm3, client.PropagationPolicy(metav1.DeletePropagationForeground)).
+					Return(apierrors.NewNotFound(corev1.Resource("configmaps"), cm3.Name)),
+			)
+
+			res, _, duplicates, err := TypedCreateOrUseAndPatch(ctx, c, []*corev1.ConfigMap{cm1, cm2, cm3}, &corev1.ConfigMap{},
+				matchNames("n1", "n2", "n3"),
+				nil,
+				nil,
+				DeleteDuplicates{client.PropagationPolicy(metav1.DeletePropagationForeground)},
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.Name).To(Equal("n2"))
+			Expect(duplicates).To(BeEmpty())
+		})
+
+		It("should return the duplicates that could not be deleted", func() {
+			gomock.InOrder(
+				c.EXPECT().Delete(ctx, cm1).Return(fmt.Errorf("some error")),
+			)
+
+			_, _, duplicates, err := TypedCreateOrUseAndPatch(ctx, c, []*corev1.ConfigMap{cm1, cm2, cm3}, &corev1.ConfigMap{},
+				matchNames("n1", "n2", "n3"),
+				nil,
+				nil,
+				DeleteDuplicates{},
+			)
+			Expect(err).To(MatchError(ContainSubstring("error deleting duplicate foo/n1")))
+			Expect(duplicates).To(Equal([]*corev1.ConfigMap{cm1, cm3}))
+		})
+
+		It("should clean up copies of the duplicates without modifying the given objects", func() {
+			c.EXPECT().Patch(ctx, gomock.Any(), gomock.Any()).Times(2)
+
+			_, _, duplicates, err := TypedCreateOrUseAndPatch(ctx, c, []*corev1.ConfigMap{cm1, cm2, cm3}, &corev1.ConfigMap{},
+				matchNames("n1", "n2", "n3"),
+				nil,
+				nil,
+				DuplicateCleanup{Policy: DuplicateCleanupLabelStale},
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(duplicates).To(HaveLen(2))
+			for _, duplicate := range duplicates {
+				Expect(duplicate.Labels).To(HaveKeyWithValue(StaleLabel, "true"))
+			}
+			Expect(cm1.Labels).To(BeEmpty(), "input objects should not be modified")
+			Expect(cm3.Labels).To(BeEmpty(), "input objects should not be modified")
+		})
+
+		It("should error if both DeleteDuplicates and a DuplicateCleanup are specified", func() {
+			_, _, _, err := TypedCreateOrUseAndPatch(ctx, c, []*corev1.ConfigMap{cm1, cm2, cm3}, &corev1.ConfigMap{},
+				matchNames("n1", "n2", "n3"),
+				nil,
+				nil,
+				DeleteDuplicates{},
+				DuplicateCleanup{Policy: DuplicateCleanupDelete, Owner: cm2},
+			)
+			Expect(err).To(MatchError("must not specify both DeleteDuplicates and DuplicateCleanup"))
+		})
+	})
+
+	Describe("CleanupDuplicates", func() {
+		var owner *corev1.ConfigMap
+		BeforeEach(func() {
+			owner = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "foo",
+					Name:      "owner",
+					UID:       "owner-uid",
+				},
+			}
+			Expect(controllerutil.SetControllerReference(owner, cm1, scheme.Scheme)).To(Succeed())
+			Expect(controllerutil.SetOwnerReference(owner, cm2, scheme.Scheme)).To(Succeed())
+		})
+
+		It("should only delete duplicates controlled by the owner", func() {
+			c.EXPECT().Delete(ctx, cm1)
+
+			res, err := CleanupDuplicates(ctx, c, []client.Object{cm1, cm2, cm3}, DuplicateCleanup{
+				Policy: DuplicateCleanupDelete,
+				Owner:  owner,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res).To(Equal(CleanupResult{
+				Deleted: []client.Object{cm1},
+				Skipped: []client.Object{cm2, cm3},
+			}))
+		})
+
+		It("should remove the controller reference of the owner when orphaning", func() {
+			c.EXPECT().Patch(ctx, cm1, gomock.Any()).Do(
+				func(_ context.Context, obj client.Object, patch client.Patch, _ ...client.PatchOption) {
+					Expect(obj.GetOwnerReferences()).To(BeEmpty())
+				})
+
+			res, err := CleanupDuplicates(ctx, c, []client.Object{cm1, cm2}, DuplicateCleanup{
+				Policy: DuplicateCleanupOrphan,
+				Owner:  owner,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res).To(Equal(CleanupResult{
+				Orphaned: []client.Object{cm1},
+				Skipped:  []client.Object{cm2},
+			}))
+		})
+
+		It("should error when deleting without an owner", func() {
+			_, err := CleanupDuplicates(ctx, c, []client.Object{cm1}, DuplicateCleanup{Policy: DuplicateCleanupDelete})
+			Expect(err).To(MatchError("must specify owner to delete duplicates"))
+		})
+
+		It("should error when orphaning without an owner", func() {
+			_, err := CleanupDuplicates(ctx, c, []client.Object{cm1}, DuplicateCleanup{Policy: DuplicateCleanupOrphan})
+			Expect(err).To(MatchError("must specify owner to orphan duplicates"))
+		})
+
+		It("should label all duplicates as stale", func() {
+			gomock.InOrder(
+				c.EXPECT().Patch(ctx, cm1, gomock.Any()),
+				c.EXPECT().Patch(ctx, cm3, gomock.Any()),
+			)
+
+			res, err := CleanupDuplicates(ctx, c, []client.Object{cm1, cm3}, DuplicateCleanup{Policy: DuplicateCleanupLabelStale})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.LabeledStale).To(Equal([]client.Object{cm1, cm3}))
+			Expect(cm3.Labels).To(HaveKeyWithValue(StaleLabel, "true"))
+		})
+	})
+
+	Describe("CreateOrUseAndPatchWithCleanup", func() {
+		It("should clean up all other objects and report the result", func() {
+			owner := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "foo", Name: "owner", UID: "owner-uid"}}
+			Expect(controllerutil.SetControllerReference(owner, cm1, scheme.Scheme)).To(Succeed())
+			Expect(controllerutil.SetControllerReference(owner, cm3, scheme.Scheme)).To(Succeed())
+			c.EXPECT().Delete(ctx, cm1)
+			c.EXPECT().Delete(ctx, cm3)
+
+			cm := &corev1.ConfigMap{}
+			result, cleanupResult, err := CreateOrUseAndPatchWithCleanup(ctx, c, []client.Object{cm1, cm2, cm3}, cm,
+				func() (bool, error) { return cm.Name == "n2", nil },
+				IsOlderThan(cm),
+				nil,
+				DuplicateCleanup{Policy: DuplicateCleanupDelete, Owner: owner},
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(controllerutil.OperationResultNone))
+			Expect(cleanupResult.Deleted).To(Equal([]client.Object{cm1, cm3}))
+		})
+	})
+})
@@ -0,0 +1,241 @@
+// Copyright 2023 IronCore authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientutils
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ironcore-dev/controller-utils/unstructuredutils"
+	"github.com/pmezard/go-difflib/difflib"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/yaml"
+)
+
+// DiffType is the type of change an ObjectDiff describes.
+type DiffType string
+
+const (
+	// DiffCreated indicates that the object does not exist and would be created.
+	DiffCreated DiffType = "Created"
+	// DiffUnchanged indicates that the object exists and would not be changed.
+	DiffUnchanged DiffType = "Unchanged"
+	// DiffChanged indicates that the object exists and would be changed.
+	DiffChanged DiffType = "Changed"
+	// DiffDeleted indicates that the object exists and would be pruned.
+	DiffDeleted DiffType = "Deleted"
+	// DiffNotFound indicates that the object does not exist and the request would fail,
+	// as its patch type requires an existing object.
+	DiffNotFound DiffType = "NotFound"
+)
+
+// ObjectDiff is the difference between the live state of an object and the state it would have after
+// executing a request.
+type ObjectDiff struct {
+	// Ref references the object.
+	Ref ObjectRef
+	// Type is the type of change.
+	Type DiffType
+	// Object is the object as returned by the server-side dry-run request.
+	// For DiffDeleted, it is the metadata of the live object. For DiffNotFound, it is nil.
+	Object client.Object
+	// Diff is a unified diff of the YAML representations of the live and the dry-run object.
+	// It is only set for DiffChanged.
+	Diff string
+}
+
+// diffIgnoredMetadataFields are metadata fields that are updated by the server on every write and thus
+// ignored when comparing the live object with the dry-run result.
+var diffIgnoredMetadataFields = []string{"managedFields", "resourceVersion", "generation"}
+
+func newObjectForGet(scheme *runtime.Scheme, obj client.Object, gvk schema.GroupVersionKind) (client.Object, error) {
+	if _, ok := obj.(*unstructured.Unstructured); ok {
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(gvk)
+		return u, nil
+	}
+
+	newObj, err := scheme.New(gvk)
+	if err != nil {
+		return nil, err
+	}
+	res, ok := newObj.(client.Object)
+	if !ok {
+		return nil, fmt.Errorf("object %T is not a client.Object", newObj)
+	}
+	return res, nil
+}
+
+func comparableObject(obj client.Object, gvk schema.GroupVersionKind) (map[string]interface{}, error) {
+	data, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	u := &unstructured.Unstructured{Object: data}
+	u.SetGroupVersionKind(gvk)
+	for _, field := range diffIgnoredMetadataFields {
+		unstructured.RemoveNestedField(u.Object, "metadata", field)
+	}
+	return u.Object, nil
+}
+
+func unifiedYAMLDiff(name string, live, dryRun map[string]interface{}) (string, error) {
+	liveData, err := yaml.Marshal(live)
+	if err != nil {
+		return "", err
+	}
+	dryRunData, err := yaml.Marshal(dryRun)
+	if err != nil {
+		return "", err
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(liveData)),
+		B:        difflib.SplitLines(string(dryRunData)),
+		FromFile: name + " (live)",
+		ToFile:   name + " (dry-run)",
+		Context:  3,
+	})
+}
+
+func diffPatchRequest(ctx context.Context, c client.Client, req PatchRequest, opts []client.PatchOption) (ObjectDiff, error) {
+	ref, err := ObjectRefFromObject(c.Scheme(), req.Object)
+	if err != nil {
+		return ObjectDiff{}, err
+	}
+
+	gvk, err := apiutil.GVKForObject(req.Object, c.Scheme())
+	if err != nil {
+		return ObjectDiff{}, err
+	}
+
+	live, err := newObjectForGet(c.Scheme(), req.Object, gvk)
+	if err != nil {
+		return ObjectDiff{}, err
+	}
+
+	dryRun := req.Object.DeepCopyObject().(client.Object)
+	dryRunOpts := append(append([]client.PatchOption{}, opts...), client.DryRunAll)
+
+	if err := c.Get(ctx, ref.Key, live); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ObjectDiff{}, fmt.Errorf("error getting live object: %w", err)
+		}
+
+		// Only apply patches are able to create objects, all other patches require the object to exist.
+		if req.Patch.Type() != client.Apply.Type() {
+			return ObjectDiff{Ref: ref, Type: DiffNotFound}, nil
+		}
+		if err := c.Patch(ctx, dryRun, req.Patch, dryRunOpts...); err != nil {
+			return ObjectDiff{}, fmt.Errorf("error creating object in dry-run mode: %w", err)
+		}
+		return ObjectDiff{Ref: ref, Type: DiffCreated, Object: dryRun}, nil
+	}
+
+	if err := c.Patch(ctx, dryRun, req.Patch, dryRunOpts...); err != nil {
+		return ObjectDiff{}, fmt.Errorf("error patching object in dry-run mode: %w", err)
+	}
+
+	liveData, err := comparableObject(live, gvk)
+	if err != nil {
+		return ObjectDiff{}, err
+	}
+	dryRunData, err := comparableObject(dryRun, gvk)
+	if err != nil {
+		return ObjectDiff{}, err
+	}
+
+	if equality.Semantic.DeepEqual(liveData, dryRunData) {
+		return ObjectDiff{Ref: ref, Type: DiffUnchanged, Object: dryRun}, nil
+	}
+
+	diff, err := unifiedYAMLDiff(ref.GroupKind.String()+" "+ref.Key.String(), liveData, dryRunData)
+	if err != nil {
+		return ObjectDiff{}, fmt.Errorf("error computing diff: %w", err)
+	}
+	return ObjectDiff{Ref: ref, Type: DiffChanged, Object: dryRun, Diff: diff}, nil
+}
+
+// DiffMultiple executes multiple PatchRequest in server-side dry-run mode and returns an ObjectDiff for each of them,
+// in the order of the requests. The given objects are not modified.
+//
+// Objects that do not exist are reported as DiffCreated for apply patches and as DiffNotFound for all other
+// patches, as executing them would fail. Existing objects are compared with the dry-run result
+// using equality.Semantic, ignoring managed fields, resource version and generation, and reported as DiffUnchanged
+// or DiffChanged, the latter including a unified diff of their YAML representations.
+//
+// If prune is non-nil, every object referenced by prune that is not part of the requests and still exists is
+// reported as DiffDeleted after the other results.
+func DiffMultiple(
+	ctx context.Context,
+	c client.Client,
+	reqs []PatchRequest,
+	prune ObjectRefSet,
+	opts ...client.PatchOption,
+) ([]ObjectDiff, error) {
+	current := NewObjectRefSet()
+	diffs := make([]ObjectDiff, 0, len(reqs))
+	for _, req := range reqs {
+		diff, err := diffPatchRequest(ctx, c, req, opts)
+		if err != nil {
+			return nil, fmt.Errorf("error diffing object %s: %w", client.ObjectKeyFromObject(req.Object), err)
+		}
+
+		current.Insert(diff.Ref)
+		diffs = append(diffs, diff)
+	}
+
+	for _, ref := range prune.List() {
+		if current.Has(ref) {
+			continue
+		}
+
+		obj, err := getObjectMetadata(ctx, c, ref)
+		if err != nil {
+			return nil, fmt.Errorf("error diffing object %s %s: %w", ref.GroupKind, ref.Key, err)
+		}
+		if obj == nil {
+			continue
+		}
+		diffs = append(diffs, ObjectDiff{Ref: ref, Type: DiffDeleted, Object: obj})
+	}
+	return diffs, nil
+}
+
+// DiffMultipleFromFile reads all objects from the given filename and diffs them using the patch provider
+// like DiffMultiple.
+func DiffMultipleFromFile(
+	ctx context.Context,
+	c client.Client,
+	filename string,
+	patchProvider PatchProvider,
+	prune ObjectRefSet,
+	opts ...client.PatchOption,
+) ([]ObjectDiff, error) {
+	objs, err := unstructuredutils.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+
+	reqs := PatchRequestsFromObjectsAndProvider(unstructuredutils.UnstructuredSliceToObjectSliceNoCopy(objs), patchProvider)
+	return DiffMultiple(ctx, c, reqs, prune, opts...)
+}
@@ -0,0 +1,167 @@
+// Copyright 2023 IronCore authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientutils_test
+
+import (
+	"context"
+
+	. "github.com/ironcore-dev/controller-utils/clientutils"
+	mockclient "github.com/ironcore-dev/controller-utils/mock/controller-runtime/client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Diff", func() {
+	var (
+		ctx  context.Context
+		ctrl *gomock.Controller
+
+		c *mockclient.MockClient
+
+		cm     *corev1.ConfigMap
+		cmRef  ObjectRef
+		secret *corev1.Secret
+	)
+	BeforeEach(func() {
+		ctx = context.Background()
+		ctrl = gomock.NewController(GinkgoT())
+
+		mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{corev1.SchemeGroupVersion})
+		mapper.Add(corev1.SchemeGroupVersion.WithKind("Secret"), meta.RESTScopeNamespace)
+
+		c = mockclient.NewMockClient(ctrl)
+		c.EXPECT().Scheme().Return(scheme.Scheme).AnyTimes()
+		c.EXPECT().RESTMapper().Return(mapper).AnyTimes()
+
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: corev1.NamespaceDefault,
+				Name:      "my-cm",
+			},
+			Data: map[string]string{"foo": "bar"},
+		}
+		cmRef = ObjectRef{GroupKind: schema.GroupKind{Kind: "ConfigMap"}, Key: client.ObjectKeyFromObject(cm)}
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: corev1.NamespaceDefault,
+				Name:      "my-secret",
+			},
+		}
+	})
+
+	expectGetLive := func(data map[string]string) *gomock.Call {
+		return c.EXPECT().Get(ctx, cmRef.Key, gomock.AssignableToTypeOf(&corev1.ConfigMap{})).DoAndReturn(
+			func(_ context.Context, key client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+				if data == nil {
+					return apierrors.NewNotFound(corev1.Resource("configmaps"), key.Name)
+				}
+				live := obj.(*corev1.ConfigMap)
+				live.Namespace, live.Name = key.Namespace, key.Name
+				live.ResourceVersion = "1"
+				live.Data = data
+				return nil
+			})
+	}
+
+	expectDryRunPatch := func() *gomock.Call {
+		return c.EXPECT().Patch(ctx, gomock.AssignableToTypeOf(&corev1.ConfigMap{}), client.Apply, client.DryRunAll).Do(
+			func(_ context.Context, obj client.Object, _ client.Patch, _ ...client.PatchOption) {
+				Expect(obj).NotTo(BeIdenticalTo(cm))
+				obj.SetResourceVersion("2")
+			})
+	}
+
+	Describe("DiffMultiple", func() {
+		It("should report objects that do not exist as created", func() {
+			gomock.InOrder(
+				expectGetLive(nil),
+				expectDryRunPatch(),
+			)
+
+			diffs, err := DiffMultiple(ctx, c, []PatchRequest{{Object: cm, Patch: client.Apply}}, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(diffs).To(HaveLen(1))
+			Expect(diffs[0].Ref).To(Equal(cmRef))
+			Expect(diffs[0].Type).To(Equal(DiffCreated))
+		})
+
+		It("should report objects that do not exist as not found for non-apply patches", func() {
+			expectGetLive(nil)
+
+			diffs, err := DiffMultiple(ctx, c, []PatchRequest{{Object: cm, Patch: client.MergeFrom(cm)}}, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(diffs).To(Equal([]ObjectDiff{{Ref: cmRef, Type: DiffNotFound}}))
+		})
+
+		It("should report objects that would not change as unchanged", func() {
+			gomock.InOrder(
+				expectGetLive(map[string]string{"foo": "bar"}),
+				expectDryRunPatch(),
+			)
+
+			diffs, err := DiffMultiple(ctx, c, []PatchRequest{{Object: cm, Patch: client.Apply}}, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(diffs).To(HaveLen(1))
+			Expect(diffs[0].Type).To(Equal(DiffUnchanged))
+			Expect(diffs[0].Diff).To(BeEmpty())
+		})
+
+		It("should report objects that would change with a unified diff", func() {
+			gomock.InOrder(
+				expectGetLive(map[string]string{"foo": "baz"}),
+				expectDryRunPatch(),
+			)
+
+			diffs, err := DiffMultiple(ctx, c, []PatchRequest{{Object: cm, Patch: client.Apply}}, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(diffs).To(HaveLen(1))
+			Expect(diffs[0].Type).To(Equal(DiffChanged))
+			Expect(diffs[0].Diff).To(And(
+				ContainSubstring("--- ConfigMap default/my-cm (live)"),
+				ContainSubstring("+++ ConfigMap default/my-cm (dry-run)"),
+				ContainSubstring("-  foo: baz"),
+				ContainSubstring("+  foo: bar"),
+			))
+			Expect(diffs[0].Diff).NotTo(ContainSubstring("resourceVersion"))
+		})
+
+		It("should report existing objects of the prune set that are not part of the requests as deleted", func() {
+			secretRef := ObjectRef{GroupKind: schema.GroupKind{Kind: "Secret"}, Key: client.ObjectKeyFromObject(secret)}
+			otherRef := ObjectRef{GroupKind: schema.GroupKind{Kind: "Secret"}, Key: client.ObjectKey{Namespace: corev1.NamespaceDefault, Name: "other"}}
+			gomock.InOrder(
+				expectGetLive(map[string]string{"foo": "bar"}),
+				expectDryRunPatch(),
+				c.EXPECT().Get(ctx, secretRef.Key, gomock.AssignableToTypeOf(&metav1.PartialObjectMetadata{})),
+				c.EXPECT().Get(ctx, otherRef.Key, gomock.AssignableToTypeOf(&metav1.PartialObjectMetadata{})).
+					Return(apierrors.NewNotFound(corev1.Resource("secrets"), otherRef.Key.Name)),
+			)
+
+			diffs, err := DiffMultiple(ctx, c, []PatchRequest{{Object: cm, Patch: client.Apply}}, NewObjectRefSet(cmRef, secretRef, otherRef))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(diffs).To(HaveLen(2))
+			Expect(diffs[0].Type).To(Equal(DiffUnchanged))
+			Expect(diffs[1].Ref).To(Equal(secretRef))
+			Expect(diffs[1].Type).To(Equal(DiffDeleted))
+		})
+	})
+})
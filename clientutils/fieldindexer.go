@@ -17,6 +17,8 @@ package clientutils
 import (
 	"context"
 	"fmt"
+	"sort"
+	"sync"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -28,8 +30,11 @@ import (
 )
 
 // SharedFieldIndexer allows registering and calling field index functions shared by different users.
+// It is safe for concurrent use.
 type SharedFieldIndexer struct {
 	indexer client.FieldIndexer
+
+	mu sync.Mutex
 	*sharedFieldIndexerMap
 }
 
@@ -42,12 +47,24 @@ func NewSharedFieldIndexer(indexer client.FieldIndexer, scheme *runtime.Scheme)
 }
 
 // Register registers the client.IndexerFunc for the given client.Object and field.
+// If there already is a registration for the client.Object and field, it errors.
+// To register an index from multiple places, declare it as FieldIndex.
 func (s *SharedFieldIndexer) Register(obj client.Object, field string, extractValue client.IndexerFunc) error {
-	updated, err := s.setIfNotPresent(obj, field, extractValue)
+	return s.register(obj, field, extractValue, nil)
+}
+
+// register registers the client.IndexerFunc for the given client.Object and field.
+// If there already is a registration with the same non-nil key, it is treated as identical and
+// no error is returned.
+func (s *SharedFieldIndexer) register(obj client.Object, field string, extractValue client.IndexerFunc, key any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, updated, err := s.setIfNotPresent(obj, field, extractValue, key)
 	if err != nil {
 		return err
 	}
-	if !updated {
+	if !updated && (key == nil || entry.key != key) {
 		return fmt.Errorf("indexer for type %T field %s already registered", obj, field)
 	}
 	return nil
@@ -58,27 +75,157 @@ func (s *SharedFieldIndexer) MustRegister(obj client.Object, field string, extra
 	utilruntime.Must(s.Register(obj, field, extractValue))
 }
 
+// Unregister removes the registration of the given client.Object and field.
+// Indices that have already been initialized via IndexField cannot be unregistered.
+// Unregistering an unknown field is a no-op.
+func (s *SharedFieldIndexer) Unregister(obj client.Object, field string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m, gvk, err := s.mapFor(obj)
+	if err != nil {
+		return err
+	}
+
+	entry := m.get(gvk, field)
+	if entry == nil {
+		return nil
+	}
+	if entry.initialized || entry.initializing {
+		return fmt.Errorf("indexer for type %T field %s is already initialized", obj, field)
+	}
+	m.delete(gvk, field)
+	return nil
+}
+
 // IndexField calls a registered client.IndexerFunc for the given client.Object and field.
 // If the object / field is unknown or its GVK could not be determined, it errors.
 func (s *SharedFieldIndexer) IndexField(ctx context.Context, obj client.Object, field string) error {
+	s.mu.Lock()
 	entry, err := s.get(obj, field)
+	s.mu.Unlock()
 	if err != nil {
 		return err
 	}
-
 	if entry == nil {
 		return fmt.Errorf("unknown field %s for type %T", field, obj)
 	}
+
+	// Serialize initializing the entry without holding the lock while calling into the indexer.
+	entry.initMu.Lock()
+	defer entry.initMu.Unlock()
+
+	s.mu.Lock()
+	// The entry may have been unregistered while waiting to initialize it.
+	if current, err := s.get(obj, field); err != nil || current != entry {
+		s.mu.Unlock()
+		return fmt.Errorf("indexer for type %T field %s was unregistered", obj, field)
+	}
 	if entry.initialized {
+		s.mu.Unlock()
 		return nil
 	}
-	if err := s.indexer.IndexField(ctx, obj, field, entry.extractValue); err != nil {
+	entry.initializing = true
+	s.mu.Unlock()
+
+	err = s.indexer.IndexField(ctx, obj, field, entry.extractValue)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry.initializing = false
+	if err != nil {
 		return err
 	}
 	entry.initialized = true
 	return nil
 }
 
+// isRegistered reports whether an indexer is registered for the given client.Object and field.
+func (s *SharedFieldIndexer) isRegistered(obj client.Object, field string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, err := s.get(obj, field)
+	if err != nil {
+		return false, err
+	}
+	return entry != nil, nil
+}
+
+// FieldIndexObjectType is the type of objects a field index is registered for.
+type FieldIndexObjectType string
+
+const (
+	// FieldIndexObjectTypeStructured is the type of structured (typed) objects.
+	FieldIndexObjectTypeStructured FieldIndexObjectType = "structured"
+	// FieldIndexObjectTypeUnstructured is the type of *unstructured.Unstructured objects.
+	FieldIndexObjectTypeUnstructured FieldIndexObjectType = "unstructured"
+	// FieldIndexObjectTypeMetadata is the type of *metav1.PartialObjectMetadata objects.
+	FieldIndexObjectTypeMetadata FieldIndexObjectType = "metadata"
+)
+
+// FieldIndexInfo describes a field index registered on a SharedFieldIndexer.
+type FieldIndexInfo struct {
+	// GroupVersionKind is the group version kind of the indexed objects.
+	GroupVersionKind schema.GroupVersionKind
+	// ObjectType is the type of the indexed objects.
+	ObjectType FieldIndexObjectType
+	// Field is the name of the indexed field.
+	Field string
+	// Initialized reports whether the index has been initialized via IndexField.
+	Initialized bool
+}
+
+// String returns a human-readable description of the index.
+func (i FieldIndexInfo) String() string {
+	return fmt.Sprintf("%s (%s) field %s", i.GroupVersionKind, i.ObjectType, i.Field)
+}
+
+// Indices returns all registered indices, sorted by group version kind, object type and field.
+func (s *SharedFieldIndexer) Indices() []FieldIndexInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var res []FieldIndexInfo
+	for objectType, m := range map[FieldIndexObjectType]*specificSharedFieldIndexerMap{
+		FieldIndexObjectTypeStructured:   s.structured,
+		FieldIndexObjectTypeUnstructured: s.unstructured,
+		FieldIndexObjectTypeMetadata:     s.metadata,
+	} {
+		for gvk, nameToEntry := range m.gvkToNameToEntry {
+			for name, entry := range nameToEntry {
+				res = append(res, FieldIndexInfo{
+					GroupVersionKind: gvk,
+					ObjectType:       objectType,
+					Field:            name,
+					Initialized:      entry.initialized,
+				})
+			}
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		a, b := res[i], res[j]
+		switch {
+		case a.GroupVersionKind != b.GroupVersionKind:
+			return a.GroupVersionKind.String() < b.GroupVersionKind.String()
+		case a.ObjectType != b.ObjectType:
+			return a.ObjectType < b.ObjectType
+		default:
+			return a.Field < b.Field
+		}
+	})
+	return res
+}
+
+// IndicesByGroupVersionKind returns all registered indices grouped by the group version kind of the indexed objects.
+func (s *SharedFieldIndexer) IndicesByGroupVersionKind() map[schema.GroupVersionKind][]FieldIndexInfo {
+	res := make(map[schema.GroupVersionKind][]FieldIndexInfo)
+	for _, info := range s.Indices() {
+		res[info.GroupVersionKind] = append(res[info.GroupVersionKind], info)
+	}
+	return res
+}
+
 type sharedFieldIndexerMap struct {
 	scheme       *runtime.Scheme
 	unstructured *specificSharedFieldIndexerMap
@@ -96,8 +243,11 @@ func newSharedFieldIndexerMap(scheme *runtime.Scheme) *sharedFieldIndexerMap {
 }
 
 type mapEntry struct {
+	initMu       sync.Mutex
+	initializing bool
 	initialized  bool
 	extractValue client.IndexerFunc
+	key          any
 }
 
 type specificSharedFieldIndexerMap struct {
@@ -112,18 +262,32 @@ func (s *specificSharedFieldIndexerMap) get(gvk schema.GroupVersionKind, name st
 	return s.gvkToNameToEntry[gvk][name]
 }
 
-func (s *specificSharedFieldIndexerMap) setIfNotPresent(gvk schema.GroupVersionKind, name string, extractValue client.IndexerFunc) (updated bool) {
+func (s *specificSharedFieldIndexerMap) delete(gvk schema.GroupVersionKind, name string) {
+	nameToEntry := s.gvkToNameToEntry[gvk]
+	delete(nameToEntry, name)
+	if len(nameToEntry) == 0 {
+		delete(s.gvkToNameToEntry, gvk)
+	}
+}
+
+func (s *specificSharedFieldIndexerMap) setIfNotPresent(
+	gvk schema.GroupVersionKind,
+	name string,
+	extractValue client.IndexerFunc,
+	key any,
+) (entry *mapEntry, updated bool) {
 	nameToEntry := s.gvkToNameToEntry[gvk]
 	if nameToEntry == nil {
 		nameToEntry = make(map[string]*mapEntry)
 		s.gvkToNameToEntry[gvk] = nameToEntry
 	}
 
-	if _, ok := nameToEntry[name]; ok {
-		return false
+	if entry, ok := nameToEntry[name]; ok {
+		return entry, false
 	}
-	nameToEntry[name] = &mapEntry{extractValue: extractValue}
-	return true
+	entry = &mapEntry{extractValue: extractValue, key: key}
+	nameToEntry[name] = entry
+	return entry, true
 }
 
 func (s *sharedFieldIndexerMap) mapFor(obj client.Object) (*specificSharedFieldIndexerMap, schema.GroupVersionKind, error) {
@@ -151,11 +315,17 @@ func (s *sharedFieldIndexerMap) get(obj client.Object, name string) (*mapEntry,
 	return m.get(gvk, name), nil
 }
 
-func (s *sharedFieldIndexerMap) setIfNotPresent(obj client.Object, name string, extractValue client.IndexerFunc) (updated bool, err error) {
+func (s *sharedFieldIndexerMap) setIfNotPresent(
+	obj client.Object,
+	name string,
+	extractValue client.IndexerFunc,
+	key any,
+) (entry *mapEntry, updated bool, err error) {
 	m, gvk, err := s.mapFor(obj)
 	if err != nil {
-		return false, err
+		return nil, false, err
 	}
 
-	return m.setIfNotPresent(gvk, name, extractValue), nil
+	entry, updated = m.setIfNotPresent(gvk, name, extractValue, key)
+	return entry, updated, nil
 }
@@ -16,6 +16,7 @@ package clientutils
 
 import (
 	"context"
+	"sync"
 
 	mockclient "github.com/ironcore-dev/controller-utils/mock/controller-runtime/client"
 	. "github.com/onsi/ginkgo/v2"
@@ -24,6 +25,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -72,6 +74,18 @@ var _ = Describe("FieldIndexer", func() {
 			Expect(idx.Register(&corev1.Pod{}, ".spec", f.Call)).To(MatchError("indexer for type *v1.Pod field .spec already registered"))
 		})
 
+		It("should not hold its lock while calling the field indexer", func() {
+			f := mockclient.NewMockIndexerFunc(ctrl)
+			idx := NewSharedFieldIndexer(fieldIndexer, scheme.Scheme)
+			fieldIndexer.EXPECT().IndexField(ctx, &corev1.Pod{}, ".spec", gomock.Any()).Do(
+				func(context.Context, client.Object, string, client.IndexerFunc) {
+					Expect(idx.Indices()).To(HaveLen(1))
+				})
+
+			Expect(idx.Register(&corev1.Pod{}, ".spec", f.Call)).To(Succeed())
+			Expect(idx.IndexField(ctx, &corev1.Pod{}, ".spec")).To(Succeed())
+		})
+
 		It("should call the index function only once", func() {
 			f := mockclient.NewMockIndexerFunc(ctrl)
 			gomock.InOrder(
@@ -153,5 +167,81 @@ var _ = Describe("FieldIndexer", func() {
 			idx := NewSharedFieldIndexer(fieldIndexer, scheme.Scheme)
 			Expect(idx.IndexField(ctx, &corev1.Pod{}, "unknown")).To(HaveOccurred())
 		})
+
+		It("should unregister a not yet initialized field", func() {
+			f := mockclient.NewMockIndexerFunc(ctrl)
+			idx := NewSharedFieldIndexer(fieldIndexer, scheme.Scheme)
+
+			Expect(idx.Register(&corev1.Pod{}, ".spec", f.Call)).To(Succeed())
+			Expect(idx.Unregister(&corev1.Pod{}, ".spec")).To(Succeed())
+			Expect(idx.Indices()).To(BeEmpty())
+			Expect(idx.Register(&corev1.Pod{}, ".spec", f.Call)).To(Succeed())
+		})
+
+		It("should error unregistering an initialized field", func() {
+			f := mockclient.NewMockIndexerFunc(ctrl)
+			fieldIndexer.EXPECT().IndexField(ctx, &corev1.Pod{}, ".spec", gomock.Any())
+			idx := NewSharedFieldIndexer(fieldIndexer, scheme.Scheme)
+
+			Expect(idx.Register(&corev1.Pod{}, ".spec", f.Call)).To(Succeed())
+			Expect(idx.IndexField(ctx, &corev1.Pod{}, ".spec")).To(Succeed())
+			Expect(idx.Unregister(&corev1.Pod{}, ".spec")).To(MatchError("indexer for type *v1.Pod field .spec is already initialized"))
+		})
+
+		It("should list the registered indices", func() {
+			f := mockclient.NewMockIndexerFunc(ctrl)
+			fieldIndexer.EXPECT().IndexField(ctx, &corev1.Pod{}, ".spec", gomock.Any())
+			idx := NewSharedFieldIndexer(fieldIndexer, scheme.Scheme)
+
+			podMetadata := &metav1.PartialObjectMetadata{TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"}}
+			Expect(idx.Register(&corev1.Pod{}, ".spec", f.Call)).To(Succeed())
+			Expect(idx.Register(&corev1.Pod{}, ".metadata", f.Call)).To(Succeed())
+			Expect(idx.Register(podMetadata, ".metadata", f.Call)).To(Succeed())
+			Expect(idx.Register(&corev1.Secret{}, ".data", f.Call)).To(Succeed())
+			Expect(idx.IndexField(ctx, &corev1.Pod{}, ".spec")).To(Succeed())
+
+			podGVK := schema.GroupVersionKind{Version: "v1", Kind: "Pod"}
+			secretGVK := schema.GroupVersionKind{Version: "v1", Kind: "Secret"}
+			Expect(idx.Indices()).To(Equal([]FieldIndexInfo{
+				{GroupVersionKind: podGVK, ObjectType: FieldIndexObjectTypeMetadata, Field: ".metadata"},
+				{GroupVersionKind: podGVK, ObjectType: FieldIndexObjectTypeStructured, Field: ".metadata"},
+				{GroupVersionKind: podGVK, ObjectType: FieldIndexObjectTypeStructured, Field: ".spec", Initialized: true},
+				{GroupVersionKind: secretGVK, ObjectType: FieldIndexObjectTypeStructured, Field: ".data"},
+			}))
+			Expect(idx.IndicesByGroupVersionKind()).To(HaveKeyWithValue(secretGVK, []FieldIndexInfo{
+				{GroupVersionKind: secretGVK, ObjectType: FieldIndexObjectTypeStructured, Field: ".data"},
+			}))
+		})
+
+		It("should be safe for concurrent use", func() {
+			f := mockclient.NewMockIndexerFunc(ctrl)
+			fieldIndexer.EXPECT().IndexField(ctx, &corev1.Pod{}, ".spec", gomock.Any()).Times(1)
+			idx := NewSharedFieldIndexer(fieldIndexer, scheme.Scheme)
+
+			var (
+				wg         sync.WaitGroup
+				registered = make(chan error, 10)
+			)
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func() {
+					defer GinkgoRecover()
+					defer wg.Done()
+					registered <- idx.Register(&corev1.Pod{}, ".spec", f.Call)
+					Expect(idx.IndexField(ctx, &corev1.Pod{}, ".spec")).To(Succeed())
+					_ = idx.Indices()
+				}()
+			}
+			wg.Wait()
+			close(registered)
+
+			var succeeded int
+			for err := range registered {
+				if err == nil {
+					succeeded++
+				}
+			}
+			Expect(succeeded).To(Equal(1))
+		})
 	})
 })
@@ -0,0 +1,165 @@
+// Copyright 2023 IronCore authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientutils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// FinalizerResult is the result of running a Finalizer.
+type FinalizerResult struct {
+	// Requeue reports whether the finalizer is not done yet and the object should be requeued.
+	Requeue bool
+	// RequeueAfter reports that the finalizer is not done yet and the object should be requeued
+	// after the given duration. Implies Requeue.
+	RequeueAfter time.Duration
+}
+
+// Done reports whether the finalizer is done.
+func (r FinalizerResult) Done() bool {
+	return !r.Requeue && r.RequeueAfter <= 0
+}
+
+// Finalizer finalizes an object that is being deleted.
+type Finalizer interface {
+	// Finalize runs the cleanup for the given object. The finalizer is only removed from the object
+	// once the returned FinalizerResult is done and no error is returned.
+	Finalize(ctx context.Context, obj client.Object) (FinalizerResult, error)
+}
+
+// FinalizerFunc is a function that implements Finalizer.
+type FinalizerFunc func(ctx context.Context, obj client.Object) (FinalizerResult, error)
+
+// Finalize implements Finalizer.
+func (f FinalizerFunc) Finalize(ctx context.Context, obj client.Object) (FinalizerResult, error) {
+	return f(ctx, obj)
+}
+
+// FinalizersResult is the result of running Finalizers.Finalize.
+type FinalizersResult struct {
+	// Updated reports whether the finalizers of the object were patched.
+	Updated bool
+	// Pending are the names of the finalizers that are not done yet and thus still present on the object.
+	Pending []string
+	// Requeue reports whether any finalizer requested a requeue.
+	Requeue bool
+	// RequeueAfter is the shortest requeue duration requested by any finalizer.
+	RequeueAfter time.Duration
+}
+
+func (r *FinalizersResult) addRequeue(res FinalizerResult) {
+	if res.Requeue {
+		r.Requeue = true
+	}
+	if res.RequeueAfter > 0 && (r.RequeueAfter <= 0 || res.RequeueAfter < r.RequeueAfter) {
+		r.RequeueAfter = res.RequeueAfter
+	}
+}
+
+// Finalizers manages multiple named Finalizer of objects.
+//
+// While an object is not being deleted, Finalizers.Finalize ensures the names of all registered finalizers are
+// present on the object. Once the object is being deleted, the registered Finalizer of all names present on the
+// object are run and the names of the ones that are done are removed.
+// Adding or removing finalizers is done with a single merge patch.
+type Finalizers struct {
+	client     client.Client
+	names      []string
+	finalizers map[string]Finalizer
+}
+
+// NewFinalizers creates new Finalizers using the given client.
+func NewFinalizers(c client.Client) *Finalizers {
+	return &Finalizers{
+		client:     c,
+		finalizers: make(map[string]Finalizer),
+	}
+}
+
+// Register registers the Finalizer with the given name.
+// Finalizers are run in the order they are registered.
+// An error is returned if there already is a Finalizer registered with the same name.
+func (f *Finalizers) Register(name string, finalizer Finalizer) error {
+	if _, ok := f.finalizers[name]; ok {
+		return fmt.Errorf("finalizer %s is already registered", name)
+	}
+
+	f.names = append(f.names, name)
+	f.finalizers[name] = finalizer
+	return nil
+}
+
+// Finalize ensures the finalizers of the given object if it is not being deleted or runs them if it is.
+//
+// If the object is being deleted, all Finalizer whose name is present on the object are run, even if any of
+// them fails. Errors of failed Finalizer are aggregated. The names of the Finalizer that are done are removed
+// from the object, even if any other Finalizer failed. Only the finalizers of the object are patched, any other
+// modification a Finalizer makes to the object is not persisted.
+func (f *Finalizers) Finalize(ctx context.Context, obj client.Object) (FinalizersResult, error) {
+	var (
+		res  FinalizersResult
+		errs []error
+		done []string
+	)
+	if !obj.GetDeletionTimestamp().IsZero() {
+		for _, name := range f.names {
+			if !controllerutil.ContainsFinalizer(obj, name) {
+				continue
+			}
+
+			finalizerRes, err := f.finalizers[name].Finalize(ctx, obj)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("error running finalizer %s: %w", name, err))
+				res.Pending = append(res.Pending, name)
+				continue
+			}
+			if !finalizerRes.Done() {
+				res.addRequeue(finalizerRes)
+				res.Pending = append(res.Pending, name)
+				continue
+			}
+			done = append(done, name)
+		}
+	}
+
+	baseObj := obj.DeepCopyObject().(client.Object)
+	if obj.GetDeletionTimestamp().IsZero() {
+		for _, name := range f.names {
+			if controllerutil.AddFinalizer(obj, name) {
+				res.Updated = true
+			}
+		}
+	} else {
+		for _, name := range done {
+			if controllerutil.RemoveFinalizer(obj, name) {
+				res.Updated = true
+			}
+		}
+	}
+
+	if res.Updated {
+		if err := f.client.Patch(ctx, obj, client.MergeFrom(baseObj)); err != nil {
+			res.Updated = false
+			errs = append(errs, fmt.Errorf("error patching finalizers: %w", err))
+		}
+	}
+	return res, errors.Join(errs...)
+}
@@ -0,0 +1,135 @@
+// Copyright 2023 IronCore authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientutils_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/ironcore-dev/controller-utils/clientutils"
+	mockclient "github.com/ironcore-dev/controller-utils/mock/controller-runtime/client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Finalizer", func() {
+	const (
+		finalizerA = "example.org/a"
+		finalizerB = "example.org/b"
+		finalizerC = "example.org/c"
+	)
+
+	var (
+		ctx  context.Context
+		ctrl *gomock.Controller
+
+		c *mockclient.MockClient
+
+		cm         *corev1.ConfigMap
+		finalizers *Finalizers
+	)
+	BeforeEach(func() {
+		ctx = context.Background()
+		ctrl = gomock.NewController(GinkgoT())
+
+		c = mockclient.NewMockClient(ctrl)
+
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: corev1.NamespaceDefault,
+				Name:      "my-cm",
+			},
+		}
+		finalizers = NewFinalizers(c)
+	})
+
+	result := func(res FinalizerResult, err error) Finalizer {
+		return FinalizerFunc(func(context.Context, client.Object) (FinalizerResult, error) {
+			return res, err
+		})
+	}
+
+	expectPatchFinalizers := func(finalizers ...string) *gomock.Call {
+		return c.EXPECT().Patch(ctx, cm, gomock.Any()).Do(
+			func(_ context.Context, obj client.Object, _ client.Patch, _ ...client.PatchOption) {
+				Expect(obj.GetFinalizers()).To(Equal(finalizers))
+			})
+	}
+
+	Describe("Register", func() {
+		It("should error if a finalizer is registered twice", func() {
+			Expect(finalizers.Register(finalizerA, result(FinalizerResult{}, nil))).To(Succeed())
+			Expect(finalizers.Register(finalizerA, result(FinalizerResult{}, nil))).To(MatchError("finalizer example.org/a is already registered"))
+		})
+	})
+
+	Describe("Finalize", func() {
+		BeforeEach(func() {
+			Expect(finalizers.Register(finalizerA, result(FinalizerResult{}, nil))).To(Succeed())
+			Expect(finalizers.Register(finalizerB, result(FinalizerResult{RequeueAfter: time.Minute}, nil))).To(Succeed())
+			Expect(finalizers.Register(finalizerC, result(FinalizerResult{}, fmt.Errorf("some error")))).To(Succeed())
+		})
+
+		It("should add all missing finalizers with a single patch if the object is not being deleted", func() {
+			cm.Finalizers = []string{finalizerB}
+			expectPatchFinalizers(finalizerB, finalizerA, finalizerC)
+
+			res, err := finalizers.Finalize(ctx, cm)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res).To(Equal(FinalizersResult{Updated: true}))
+		})
+
+		It("should not patch if all finalizers are present", func() {
+			cm.Finalizers = []string{finalizerA, finalizerB, finalizerC}
+
+			res, err := finalizers.Finalize(ctx, cm)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res).To(Equal(FinalizersResult{}))
+		})
+
+		It("should run the finalizers and only remove the ones that are done if the object is being deleted", func() {
+			now := metav1.Now()
+			cm.DeletionTimestamp = &now
+			cm.Finalizers = []string{finalizerA, finalizerB, finalizerC, "other"}
+			expectPatchFinalizers(finalizerB, finalizerC, "other")
+
+			res, err := finalizers.Finalize(ctx, cm)
+			Expect(err).To(MatchError("error running finalizer example.org/c: some error"))
+			Expect(res).To(Equal(FinalizersResult{
+				Updated:      true,
+				Pending:      []string{finalizerB, finalizerC},
+				RequeueAfter: time.Minute,
+			}))
+		})
+
+		It("should not run finalizers that are not present on the object", func() {
+			now := metav1.Now()
+			cm.DeletionTimestamp = &now
+			cm.Finalizers = []string{finalizerB}
+
+			res, err := finalizers.Finalize(ctx, cm)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res).To(Equal(FinalizersResult{
+				Pending:      []string{finalizerB},
+				RequeueAfter: time.Minute,
+			}))
+		})
+	})
+})
@@ -0,0 +1,129 @@
+// Copyright 2023 IronCore authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientutils
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ironcore-dev/controller-utils/metautils"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// IndexKeyFunc returns the index key to look up the objects referencing the given object.
+type IndexKeyFunc func(obj client.Object) string
+
+// IndexKeyName is an IndexKeyFunc that returns the name of the object.
+func IndexKeyName(obj client.Object) string {
+	return obj.GetName()
+}
+
+// IndexKeyNamespacedName is an IndexKeyFunc that returns the 'namespace/name' of the object.
+func IndexKeyNamespacedName(obj client.Object) string {
+	return client.ObjectKeyFromObject(obj).String()
+}
+
+// IndexedFieldMapFunc returns a handler.MapFunc that maps an object to reconcile.Request for all objects
+// of the given referencingType that reference it via the given field.
+//
+// The field has to be registered for the referencingType on the given SharedFieldIndexer.
+// The referencing objects are listed using client.MatchingFields with the key returned by keyFunc for
+// the mapped object. If keyFunc is nil, IndexKeyName is used. If both the referencingType and the mapped
+// object are namespaced, only objects in the namespace of the mapped object are listed. Whether the
+// referencingType is namespaced is determined using the RESTMapper of the client.
+//
+// The referencingType may be a typed, *unstructured.Unstructured or *metav1.PartialObjectMetadata object.
+// In the latter cases, its group version kind has to be set. Errors listing the referencing objects are logged
+// using the logger of the context.
+func IndexedFieldMapFunc(
+	c client.Client,
+	indexer *SharedFieldIndexer,
+	referencingType client.Object,
+	field string,
+	keyFunc IndexKeyFunc,
+) (handler.MapFunc, error) {
+	ok, err := indexer.isRegistered(referencingType, field)
+	if err != nil {
+		return nil, fmt.Errorf("error getting indexer for type %T field %s: %w", referencingType, field, err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown field %s for type %T", field, referencingType)
+	}
+
+	// Validate that a list can be created upfront to not have to fail in the map func.
+	if _, _, err := metautils.NewListForObject(c.Scheme(), referencingType); err != nil {
+		return nil, err
+	}
+
+	namespaced, err := c.IsObjectNamespaced(referencingType)
+	if err != nil {
+		return nil, fmt.Errorf("error determining whether type %T is namespaced: %w", referencingType, err)
+	}
+
+	if keyFunc == nil {
+		keyFunc = IndexKeyName
+	}
+
+	return func(ctx context.Context, obj client.Object) []reconcile.Request {
+		log := log.FromContext(ctx)
+
+		_, list, err := metautils.NewListForObject(c.Scheme(), referencingType)
+		if err != nil {
+			log.Error(err, "Error creating list", "Type", fmt.Sprintf("%T", referencingType))
+			return nil
+		}
+
+		opts := []client.ListOption{client.MatchingFields{field: keyFunc(obj)}}
+		if namespace := obj.GetNamespace(); namespaced && namespace != "" {
+			opts = append(opts, client.InNamespace(namespace))
+		}
+		if err := c.List(ctx, list, opts...); err != nil {
+			log.Error(err, "Error listing referencing objects", "Field", field)
+			return nil
+		}
+
+		var reqs []reconcile.Request
+		if err := metautils.EachListItem(list, func(item client.Object) error {
+			reqs = append(reqs, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: item.GetNamespace(), Name: item.GetName()},
+			})
+			return nil
+		}); err != nil {
+			log.Error(err, "Error iterating list")
+			return nil
+		}
+		return reqs
+	}, nil
+}
+
+// EnqueueRequestsForIndexedField returns a handler.EventHandler that enqueues reconcile.Request for all
+// objects referencing the object an event occurs for. See IndexedFieldMapFunc for more.
+func EnqueueRequestsForIndexedField(
+	c client.Client,
+	indexer *SharedFieldIndexer,
+	referencingType client.Object,
+	field string,
+	keyFunc IndexKeyFunc,
+) (handler.EventHandler, error) {
+	mapFunc, err := IndexedFieldMapFunc(c, indexer, referencingType, field, keyFunc)
+	if err != nil {
+		return nil, err
+	}
+	return handler.EnqueueRequestsFromMapFunc(mapFunc), nil
+}
@@ -0,0 +1,127 @@
+// Copyright 2023 IronCore authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientutils_test
+
+import (
+	"context"
+
+	. "github.com/ironcore-dev/controller-utils/clientutils"
+	mockclient "github.com/ironcore-dev/controller-utils/mock/controller-runtime/client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("Mapper", func() {
+	const field = "spec.volumes.secret.secretName"
+
+	var (
+		ctx  context.Context
+		ctrl *gomock.Controller
+
+		c       *mockclient.MockClient
+		indexer *SharedFieldIndexer
+
+		secret *corev1.Secret
+	)
+	BeforeEach(func() {
+		ctx = context.Background()
+		ctrl = gomock.NewController(GinkgoT())
+
+		c = mockclient.NewMockClient(ctrl)
+		c.EXPECT().Scheme().Return(scheme.Scheme).AnyTimes()
+		indexer = NewSharedFieldIndexer(mockclient.NewMockFieldIndexer(ctrl), scheme.Scheme)
+
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: corev1.NamespaceDefault,
+				Name:      "my-secret",
+			},
+		}
+	})
+
+	Describe("IndexedFieldMapFunc", func() {
+		It("should error if the field is not registered", func() {
+			_, err := IndexedFieldMapFunc(c, indexer, &corev1.Pod{}, field, nil)
+			Expect(err).To(MatchError("unknown field spec.volumes.secret.secretName for type *v1.Pod"))
+		})
+
+		It("should map an object to the typed objects referencing it", func() {
+			Expect(indexer.Register(&corev1.Pod{}, field, func(client.Object) []string { return nil })).To(Succeed())
+			c.EXPECT().IsObjectNamespaced(&corev1.Pod{}).Return(true, nil)
+			c.EXPECT().List(ctx, &corev1.PodList{}, client.MatchingFields{field: "my-secret"}, client.InNamespace(corev1.NamespaceDefault)).Do(
+				func(_ context.Context, list client.ObjectList, _ ...client.ListOption) {
+					list.(*corev1.PodList).Items = []corev1.Pod{
+						{ObjectMeta: metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: "pod-1"}},
+						{ObjectMeta: metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: "pod-2"}},
+					}
+				})
+
+			mapFunc, err := IndexedFieldMapFunc(c, indexer, &corev1.Pod{}, field, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mapFunc(ctx, secret)).To(Equal([]reconcile.Request{
+				{NamespacedName: types.NamespacedName{Namespace: corev1.NamespaceDefault, Name: "pod-1"}},
+				{NamespacedName: types.NamespacedName{Namespace: corev1.NamespaceDefault, Name: "pod-2"}},
+			}))
+		})
+
+		It("should map an object to the unstructured objects referencing it", func() {
+			referencingType := &unstructured.Unstructured{}
+			referencingType.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Pod"))
+			Expect(indexer.Register(referencingType, field, func(client.Object) []string { return nil })).To(Succeed())
+			c.EXPECT().IsObjectNamespaced(referencingType).Return(true, nil)
+
+			c.EXPECT().List(ctx, gomock.AssignableToTypeOf(&unstructured.UnstructuredList{}), client.MatchingFields{field: "default/my-secret"}, client.InNamespace(corev1.NamespaceDefault)).Do(
+				func(_ context.Context, list client.ObjectList, _ ...client.ListOption) {
+					Expect(list.GetObjectKind().GroupVersionKind()).To(Equal(corev1.SchemeGroupVersion.WithKind("Pod")))
+					pod := unstructured.Unstructured{}
+					pod.SetNamespace(corev1.NamespaceDefault)
+					pod.SetName("pod-1")
+					list.(*unstructured.UnstructuredList).Items = []unstructured.Unstructured{pod}
+				})
+
+			mapFunc, err := IndexedFieldMapFunc(c, indexer, referencingType, field, IndexKeyNamespacedName)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mapFunc(ctx, secret)).To(Equal([]reconcile.Request{
+				{NamespacedName: types.NamespacedName{Namespace: corev1.NamespaceDefault, Name: "pod-1"}},
+			}))
+		})
+
+		It("should not restrict the namespace if the referencing type is cluster-scoped", func() {
+			const pvField = "spec.csi.nodePublishSecretRef"
+			Expect(indexer.Register(&corev1.PersistentVolume{}, pvField, func(client.Object) []string { return nil })).To(Succeed())
+			c.EXPECT().IsObjectNamespaced(&corev1.PersistentVolume{}).Return(false, nil)
+			c.EXPECT().List(ctx, &corev1.PersistentVolumeList{}, client.MatchingFields{pvField: "default/my-secret"}).Do(
+				func(_ context.Context, list client.ObjectList, _ ...client.ListOption) {
+					list.(*corev1.PersistentVolumeList).Items = []corev1.PersistentVolume{
+						{ObjectMeta: metav1.ObjectMeta{Name: "pv-1"}},
+					}
+				})
+
+			mapFunc, err := IndexedFieldMapFunc(c, indexer, &corev1.PersistentVolume{}, pvField, IndexKeyNamespacedName)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mapFunc(ctx, secret)).To(Equal([]reconcile.Request{
+				{NamespacedName: types.NamespacedName{Name: "pv-1"}},
+			}))
+		})
+	})
+})
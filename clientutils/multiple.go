@@ -0,0 +1,413 @@
+// Copyright 2023 IronCore authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientutils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultParallelism is the number of workers used by the parallel multi-object operations
+// if no Parallelism option is specified.
+const DefaultParallelism = 10
+
+// MultipleOptions are options for multi-object operations.
+type MultipleOptions struct {
+	// Parallelism is the maximum number of requests running at the same time.
+	// If zero, the parallel multi-object operations use DefaultParallelism and the
+	// sequential ones process one object after the other.
+	Parallelism int
+	// ContinueOnError makes sequential multi-object operations process all objects
+	// instead of aborting on the first error.
+	ContinueOnError bool
+	// KindPriorities are the priorities to order objects by. If nil, objects are not ordered.
+	KindPriorities KindPriorities
+	// EstablishTimeout is the maximum duration to wait for a CustomResourceDefinition to become established.
+	EstablishTimeout time.Duration
+}
+
+// ApplyOptions applies the given MultipleOption options to the MultipleOptions.
+func (o *MultipleOptions) ApplyOptions(opts []MultipleOption) *MultipleOptions {
+	for _, opt := range opts {
+		opt.ApplyToMultiple(o)
+	}
+	return o
+}
+
+// MultipleOption is an option for multi-object operations.
+//
+// The options also implement the client options of the operations they apply to, so they can be passed
+// alongside the regular client options. They are filtered out before the options are handed to the client.
+type MultipleOption interface {
+	// ApplyToMultiple applies the option to the given MultipleOptions.
+	ApplyToMultiple(o *MultipleOptions)
+}
+
+// Parallelism limits the number of requests a multi-object operation runs at the same time.
+//
+// For the parallel multi-object operations, it overrides DefaultParallelism.
+// The sequential multi-object operations (CreateMultiple, PatchMultiple and DeleteMultiple) process
+// one object after the other by default. If Parallelism is specified, they process the objects of the
+// same processing tier in parallel instead. Unless ContinueOnError is specified, the objects already
+// started when an error occurs are completed before the first error is returned.
+type Parallelism int
+
+// ApplyToMultiple implements MultipleOption.
+func (p Parallelism) ApplyToMultiple(o *MultipleOptions) {
+	o.Parallelism = int(p)
+}
+
+// ApplyToCreate implements client.CreateOption.
+func (p Parallelism) ApplyToCreate(*client.CreateOptions) {}
+
+// ApplyToGet implements client.GetOption.
+func (p Parallelism) ApplyToGet(*client.GetOptions) {}
+
+// ApplyToPatch implements client.PatchOption.
+func (p Parallelism) ApplyToPatch(*client.PatchOptions) {}
+
+// ApplyToDelete implements client.DeleteOption.
+func (p Parallelism) ApplyToDelete(*client.DeleteOptions) {}
+
+// ContinueOnError makes a multi-object operation process all objects instead of aborting on the first error.
+// The errors of all failed objects are reported as *MultipleError.
+// The parallel multi-object operations always continue on error.
+var ContinueOnError = continueOnError{}
+
+type continueOnError struct{}
+
+// ApplyToMultiple implements MultipleOption.
+func (continueOnError) ApplyToMultiple(o *MultipleOptions) {
+	o.ContinueOnError = true
+}
+
+// ApplyToCreate implements client.CreateOption.
+func (continueOnError) ApplyToCreate(*client.CreateOptions) {}
+
+// ApplyToPatch implements client.PatchOption.
+func (continueOnError) ApplyToPatch(*client.PatchOptions) {}
+
+// ApplyToDelete implements client.DeleteOption.
+func (continueOnError) ApplyToDelete(*client.DeleteOptions) {}
+
+// splitMultipleOptions separates MultipleOption options from the remaining client options.
+func splitMultipleOptions[O any](opts []O) (*MultipleOptions, []O) {
+	o := &MultipleOptions{}
+	var rest []O
+	for _, opt := range opts {
+		if multipleOpt, ok := any(opt).(MultipleOption); ok {
+			multipleOpt.ApplyToMultiple(o)
+			continue
+		}
+		rest = append(rest, opt)
+	}
+	return o, rest
+}
+
+// ObjectResult is the outcome of a multi-object operation for a single object.
+type ObjectResult struct {
+	// Object is the object the operation was run for.
+	Object client.Object
+	// Err is the error that occurred for the object, if any.
+	Err error
+}
+
+// ObjectError is an error that occurred for a single object of a multi-object operation.
+type ObjectError struct {
+	// Ref references the failed object.
+	// If the kind of the object could not be determined, only the key is set.
+	Ref ObjectRef
+	// Object is the failed object.
+	Object client.Object
+	// Err is the underlying error.
+	Err error
+}
+
+// Error implements error.
+func (e *ObjectError) Error() string {
+	if e.Ref.GroupKind.Empty() {
+		return fmt.Sprintf("%s: %v", e.Ref.Key, e.Err)
+	}
+	return fmt.Sprintf("%s %s: %v", e.Ref.GroupKind, e.Ref.Key, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ObjectError) Unwrap() error {
+	return e.Err
+}
+
+// MultipleError is the aggregate error of a multi-object operation.
+//
+// It unwraps to the underlying errors of all failed objects, so errors.Is reports whether any of
+// the objects failed with the given error. Checks based on errors.As, like apierrors.IsNotFound, only
+// inspect the first matching error and thus the first failed object returning an API status.
+// To check the errors of all failed objects, use FailedWith.
+type MultipleError struct {
+	// Op is the operation that was run, e.g. 'creating'.
+	Op string
+	// Errors are the errors of the failed objects, in the order of the given objects.
+	Errors []ObjectError
+	// Succeeded are the objects the operation succeeded for, in the order of the given objects.
+	Succeeded []client.Object
+}
+
+// Error implements error.
+func (e *MultipleError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for i := range e.Errors {
+		msgs = append(msgs, e.Errors[i].Error())
+	}
+	return fmt.Sprintf("error %s %d of %d object(s): [%s]",
+		e.Op,
+		len(e.Errors),
+		len(e.Errors)+len(e.Succeeded),
+		strings.Join(msgs, ", "),
+	)
+}
+
+// Unwrap returns the errors of all failed objects.
+func (e *MultipleError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for i := range e.Errors {
+		errs = append(errs, &e.Errors[i])
+	}
+	return errs
+}
+
+// FailedWith returns the errors of all failed objects whose underlying error matches the given function,
+// e.g. apierrors.IsNotFound.
+func (e *MultipleError) FailedWith(match func(error) bool) []ObjectError {
+	var res []ObjectError
+	for _, err := range e.Errors {
+		if match(err.Err) {
+			res = append(res, err)
+		}
+	}
+	return res
+}
+
+// Failed returns all objects the operation failed for.
+func (e *MultipleError) Failed() []client.Object {
+	objs := make([]client.Object, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		objs = append(objs, err.Object)
+	}
+	return objs
+}
+
+// FailedRefs returns an ObjectRefSet of all objects the operation failed for.
+func (e *MultipleError) FailedRefs() ObjectRefSet {
+	s := NewObjectRefSet()
+	for _, err := range e.Errors {
+		s.Insert(err.Ref)
+	}
+	return s
+}
+
+// aggregateResults creates a *MultipleError from the given results.
+// If there are no failed results, nil is returned.
+func aggregateResults(c client.Client, op string, results []ObjectResult) error {
+	var (
+		errs      []ObjectError
+		succeeded []client.Object
+	)
+	for _, res := range results {
+		if res.Err == nil {
+			succeeded = append(succeeded, res.Object)
+			continue
+		}
+
+		ref, err := ObjectRefFromObject(c.Scheme(), res.Object)
+		if err != nil {
+			ref = ObjectRef{Key: client.ObjectKeyFromObject(res.Object)}
+		}
+		errs = append(errs, ObjectError{Ref: ref, Object: res.Object, Err: res.Err})
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return &MultipleError{Op: op, Errors: errs, Succeeded: succeeded}
+}
+
+// runFunc runs f for the objects with the given indices and writes the outcome into results.
+type runFunc func(ctx context.Context, o *MultipleOptions, objs []client.Object, indices []int, results []ObjectResult, f func(i int) error)
+
+// runSequential runs f for the given indices, one after the other.
+// Once the context is done, no further objects are processed and the remaining ones report the context error.
+func runSequential(ctx context.Context, _ *MultipleOptions, objs []client.Object, indices []int, results []ObjectResult, f func(i int) error) {
+	for _, i := range indices {
+		if err := ctx.Err(); err != nil {
+			results[i] = ObjectResult{Object: objs[i], Err: err}
+			continue
+		}
+		results[i] = ObjectResult{Object: objs[i], Err: f(i)}
+	}
+}
+
+// runParallel runs f for the given indices with the configured parallelism.
+// Once the context is done, no further objects are processed and the remaining ones report the context error.
+func runParallel(ctx context.Context, o *MultipleOptions, objs []client.Object, indices []int, results []ObjectResult, f func(i int) error) {
+	parallelism := o.Parallelism
+	if parallelism <= 0 {
+		parallelism = DefaultParallelism
+	}
+
+	done := make([]bool, len(indices))
+	workqueue.ParallelizeUntil(ctx, parallelism, len(indices), func(n int) {
+		if err := ctx.Err(); err != nil {
+			return
+		}
+		i := indices[n]
+		results[i] = ObjectResult{Object: objs[i], Err: f(i)}
+		done[n] = true
+	})
+
+	for n, i := range indices {
+		if !done[n] {
+			results[i] = ObjectResult{Object: objs[i], Err: ctx.Err()}
+		}
+	}
+}
+
+// sequentialRunFunc returns the runFunc of the sequential multi-object operations.
+// The objects are only processed in parallel if a Parallelism is specified.
+func sequentialRunFunc(o *MultipleOptions) runFunc {
+	if o.Parallelism > 0 {
+		return runParallel
+	}
+	return runSequential
+}
+
+// runTier runs f for the objects with the given indices and returns the index and error of the
+// first failed object in the order of the indices. If no object failed, -1 and nil are returned.
+// Without Parallelism, the objects are processed one after the other, stopping on the first error.
+// With Parallelism, all objects are processed in parallel before the first error is returned.
+func runTier(ctx context.Context, o *MultipleOptions, objs []client.Object, indices []int, f func(i int) error) (int, error) {
+	if o.Parallelism <= 0 {
+		for _, i := range indices {
+			if err := f(i); err != nil {
+				return i, err
+			}
+		}
+		return -1, nil
+	}
+
+	results := make([]ObjectResult, len(objs))
+	runParallel(ctx, o, objs, indices, results, f)
+	for _, i := range indices {
+		if err := results[i].Err; err != nil {
+			return i, err
+		}
+	}
+	return -1, nil
+}
+
+// runMultiple runs f for the index of each object using run, one processing tier after the other.
+// If establish is set, any CustomResourceDefinition of a tier is waited for to become established
+// before continuing with the next tier.
+// The outcome of each object is reported in the order of the given objects.
+func runMultiple(
+	ctx context.Context,
+	c client.Client,
+	o *MultipleOptions,
+	objs []client.Object,
+	reverse, establish bool,
+	run runFunc,
+	f func(i int) error,
+) ([]ObjectResult, error) {
+	tiers, err := processingTiers(c, o, objs, reverse)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]ObjectResult, len(objs))
+	for _, tier := range tiers {
+		run(ctx, o, objs, tier, results, f)
+		if establish {
+			establishTier(ctx, c, o, objs, tier, results)
+		}
+	}
+	return results, nil
+}
+
+// CreateMultipleParallel creates multiple objects in parallel using the given client and options.
+// The number of parallel requests can be limited via the Parallelism option and objects can be ordered
+// using OrderByKind. In the latter case, objects of the same priority are created in parallel.
+// In contrast to CreateMultiple, all objects are processed regardless of errors and the outcome for
+// each object is reported in the order of the given objects. Any failures are reported as *MultipleError.
+func CreateMultipleParallel(ctx context.Context, c client.Client, objs []client.Object, opts ...client.CreateOption) ([]ObjectResult, error) {
+	o, opts := splitMultipleOptions(opts)
+	results, err := runMultiple(ctx, c, o, objs, false, true, runParallel, func(i int) error {
+		return c.Create(ctx, objs[i], opts...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, aggregateResults(c, "creating", results)
+}
+
+// GetMultipleParallel gets multiple objects in parallel using the given client and options.
+// The results are written back into the given GetRequest.
+// The number of parallel requests can be limited via the Parallelism option.
+// In contrast to GetMultiple, all requests are processed regardless of errors and the outcome for
+// each request is reported in the order of the given requests. Any failures are reported as *MultipleError.
+func GetMultipleParallel(ctx context.Context, c client.Client, reqs []GetRequest, opts ...client.GetOption) ([]ObjectResult, error) {
+	o, opts := splitMultipleOptions(opts)
+	results, err := runMultiple(ctx, c, o, ObjectsFromGetRequests(reqs), false, false, runParallel, func(i int) error {
+		return c.Get(ctx, reqs[i].Key, reqs[i].Object, opts...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, aggregateResults(c, "getting", results)
+}
+
+// PatchMultipleParallel executes multiple PatchRequest in parallel with the given client.PatchOption.
+// The number of parallel requests can be limited via the Parallelism option and objects can be ordered
+// using OrderByKind. In the latter case, objects of the same priority are patched in parallel.
+// In contrast to PatchMultiple, all requests are processed regardless of errors and the outcome for
+// each request is reported in the order of the given requests. Any failures are reported as *MultipleError.
+func PatchMultipleParallel(ctx context.Context, c client.Client, reqs []PatchRequest, opts ...client.PatchOption) ([]ObjectResult, error) {
+	o, opts := splitMultipleOptions(opts)
+	results, err := runMultiple(ctx, c, o, ObjectsFromPatchRequests(reqs), false, true, runParallel, func(i int) error {
+		return c.Patch(ctx, reqs[i].Object, reqs[i].Patch, opts...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, aggregateResults(c, "patching", results)
+}
+
+// DeleteMultipleParallel deletes multiple given client.Object objects in parallel using the given
+// client.DeleteOption options.
+// The number of parallel requests can be limited via the Parallelism option and objects can be ordered
+// using OrderByKind. In the latter case, objects of the same priority are deleted in parallel.
+// In contrast to DeleteMultiple, all objects are processed regardless of errors and the outcome for
+// each object is reported in the order of the given objects. Any failures are reported as *MultipleError.
+func DeleteMultipleParallel(ctx context.Context, c client.Client, objs []client.Object, opts ...client.DeleteOption) ([]ObjectResult, error) {
+	o, opts := splitMultipleOptions(opts)
+	results, err := runMultiple(ctx, c, o, objs, true, false, runParallel, func(i int) error {
+		return c.Delete(ctx, objs[i], opts...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, aggregateResults(c, "deleting", results)
+}
@@ -0,0 +1,280 @@
+// Copyright 2023 IronCore authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientutils_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/ironcore-dev/controller-utils/clientutils"
+	mockclient "github.com/ironcore-dev/controller-utils/mock/controller-runtime/client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Multiple", func() {
+	var (
+		ctx  context.Context
+		ctrl *gomock.Controller
+
+		c *mockclient.MockClient
+
+		cm, otherCM *corev1.ConfigMap
+	)
+	BeforeEach(func() {
+		ctx = context.Background()
+		ctrl = gomock.NewController(GinkgoT())
+
+		c = mockclient.NewMockClient(ctrl)
+		c.EXPECT().Scheme().Return(scheme.Scheme).AnyTimes()
+
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: corev1.NamespaceDefault,
+				Name:      "my-cm",
+			},
+		}
+		otherCM = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: corev1.NamespaceDefault,
+				Name:      "my-other-cm",
+			},
+		}
+	})
+
+	Describe("MultipleError", func() {
+		var (
+			cmGK       schema.GroupKind
+			notFound   error
+			someErr    error
+			multiError *MultipleError
+		)
+		BeforeEach(func() {
+			cmGK = schema.GroupKind{Kind: "ConfigMap"}
+			notFound = apierrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, "my-cm")
+			someErr = fmt.Errorf("some error")
+			multiError = &MultipleError{
+				Op: "deleting",
+				Errors: []ObjectError{
+					{
+						Ref:    ObjectRef{GroupKind: cmGK, Key: client.ObjectKeyFromObject(cm)},
+						Object: cm,
+						Err:    notFound,
+					},
+					{
+						Ref:    ObjectRef{GroupKind: cmGK, Key: client.ObjectKeyFromObject(otherCM)},
+						Object: otherCM,
+						Err:    someErr,
+					},
+				},
+			}
+		})
+
+		It("should report all failed objects in its message", func() {
+			Expect(multiError).To(MatchError(`error deleting 2 of 2 object(s): [ConfigMap default/my-cm: configmaps "my-cm" not found, ConfigMap default/my-other-cm: some error]`))
+		})
+
+		It("should unwrap to the underlying errors", func() {
+			Expect(errors.Is(multiError, someErr)).To(BeTrue())
+			Expect(apierrors.IsNotFound(multiError)).To(BeTrue())
+
+			var objErr *ObjectError
+			Expect(errors.As(multiError, &objErr)).To(BeTrue())
+			Expect(objErr.Object).To(BeIdenticalTo(cm))
+		})
+
+		It("should return the errors of all failed objects matching the given function", func() {
+			conflict := apierrors.NewConflict(schema.GroupResource{Resource: "configmaps"}, "my-cm", someErr)
+			multiError.Errors[0].Err = conflict
+			multiError.Errors[1].Err = notFound
+
+			Expect(apierrors.IsNotFound(multiError)).To(BeFalse())
+			Expect(errors.Is(multiError, notFound)).To(BeTrue())
+			Expect(multiError.FailedWith(apierrors.IsNotFound)).To(Equal([]ObjectError{multiError.Errors[1]}))
+			Expect(multiError.FailedWith(apierrors.IsConflict)).To(Equal([]ObjectError{multiError.Errors[0]}))
+			Expect(multiError.FailedWith(apierrors.IsAlreadyExists)).To(BeEmpty())
+		})
+
+		It("should return the failed objects and references", func() {
+			Expect(multiError.Failed()).To(Equal([]client.Object{cm, otherCM}))
+			Expect(multiError.FailedRefs()).To(Equal(NewObjectRefSet(
+				ObjectRef{GroupKind: cmGK, Key: client.ObjectKeyFromObject(cm)},
+				ObjectRef{GroupKind: cmGK, Key: client.ObjectKeyFromObject(otherCM)},
+			)))
+		})
+	})
+
+	Describe("CreateMultipleParallel", func() {
+		It("should create all objects and report the outcome for each of them", func() {
+			someErr := fmt.Errorf("some error")
+			c.EXPECT().Create(ctx, cm).Return(someErr)
+			c.EXPECT().Create(ctx, otherCM)
+
+			res, err := CreateMultipleParallel(ctx, c, []client.Object{cm, otherCM})
+			Expect(errors.Is(err, someErr)).To(BeTrue())
+			Expect(res).To(Equal([]ObjectResult{
+				{Object: cm, Err: someErr},
+				{Object: otherCM},
+			}))
+
+			var multiErr *MultipleError
+			Expect(errors.As(err, &multiErr)).To(BeTrue())
+			Expect(multiErr.Failed()).To(Equal([]client.Object{cm}))
+			Expect(multiErr.Succeeded).To(Equal([]client.Object{otherCM}))
+		})
+
+		It("should not forward the parallelism option to the client", func() {
+			c.EXPECT().Create(ctx, cm, client.FieldOwner("owner"))
+			c.EXPECT().Create(ctx, otherCM, client.FieldOwner("owner"))
+
+			_, err := CreateMultipleParallel(ctx, c, []client.Object{cm, otherCM}, Parallelism(1), client.FieldOwner("owner"))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should not run more requests than the specified parallelism at the same time", func() {
+			const (
+				noOfObjects = 20
+				parallelism = 3
+			)
+			var (
+				objs    []client.Object
+				running int32
+				maxSeen int32
+			)
+			for i := 0; i < noOfObjects; i++ {
+				objs = append(objs, &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: corev1.NamespaceDefault,
+						Name:      fmt.Sprintf("cm-%d", i),
+					},
+				})
+			}
+			c.EXPECT().Create(ctx, gomock.Any()).Times(noOfObjects).DoAndReturn(
+				func(context.Context, client.Object, ...client.CreateOption) error {
+					n := atomic.AddInt32(&running, 1)
+					defer atomic.AddInt32(&running, -1)
+					for {
+						seen := atomic.LoadInt32(&maxSeen)
+						if n <= seen || atomic.CompareAndSwapInt32(&maxSeen, seen, n) {
+							break
+						}
+					}
+					time.Sleep(5 * time.Millisecond)
+					return nil
+				})
+
+			_, err := CreateMultipleParallel(ctx, c, objs, Parallelism(parallelism))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(atomic.LoadInt32(&maxSeen)).To(BeNumerically("<=", parallelism))
+		})
+
+		It("should report the context error for objects not processed due to cancellation", func() {
+			ctx, cancel := context.WithCancel(ctx)
+			cancel()
+
+			res, err := CreateMultipleParallel(ctx, c, []client.Object{cm, otherCM})
+			Expect(err).To(MatchError(context.Canceled))
+			Expect(res).To(Equal([]ObjectResult{
+				{Object: cm, Err: context.Canceled},
+				{Object: otherCM, Err: context.Canceled},
+			}))
+		})
+	})
+
+	Describe("GetMultipleParallel", func() {
+		It("should get all objects", func() {
+			c.EXPECT().Get(ctx, client.ObjectKeyFromObject(cm), cm)
+			c.EXPECT().Get(ctx, client.ObjectKeyFromObject(otherCM), otherCM)
+
+			res, err := GetMultipleParallel(ctx, c, []GetRequest{GetRequestFromObject(cm), GetRequestFromObject(otherCM)})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res).To(Equal([]ObjectResult{{Object: cm}, {Object: otherCM}}))
+		})
+	})
+
+	Describe("PatchMultipleParallel", func() {
+		It("should patch all objects and report the outcome for each of them", func() {
+			someErr := fmt.Errorf("some error")
+			c.EXPECT().Patch(ctx, cm, client.Apply)
+			c.EXPECT().Patch(ctx, otherCM, client.Merge).Return(someErr)
+
+			res, err := PatchMultipleParallel(ctx, c, []PatchRequest{
+				{Object: cm, Patch: client.Apply},
+				{Object: otherCM, Patch: client.Merge},
+			})
+			Expect(errors.Is(err, someErr)).To(BeTrue())
+			Expect(res).To(Equal([]ObjectResult{
+				{Object: cm},
+				{Object: otherCM, Err: someErr},
+			}))
+		})
+	})
+
+	Describe("DeleteMultipleParallel", func() {
+		It("should delete all objects", func() {
+			c.EXPECT().Delete(ctx, cm)
+			c.EXPECT().Delete(ctx, otherCM)
+
+			res, err := DeleteMultipleParallel(ctx, c, []client.Object{cm, otherCM}, Parallelism(2))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res).To(Equal([]ObjectResult{{Object: cm}, {Object: otherCM}}))
+		})
+	})
+
+	Describe("Parallelism", func() {
+		It("should make CreateMultiple create the objects in parallel", func() {
+			var arrived sync.WaitGroup
+			arrived.Add(2)
+			c.EXPECT().Create(ctx, gomock.Any()).Times(2).DoAndReturn(
+				func(context.Context, client.Object, ...client.CreateOption) error {
+					arrived.Done()
+					done := make(chan struct{})
+					go func() {
+						arrived.Wait()
+						close(done)
+					}()
+					select {
+					case <-done:
+						return nil
+					case <-time.After(time.Second):
+						return fmt.Errorf("requests did not run in parallel")
+					}
+				})
+
+			Expect(CreateMultiple(ctx, c, []client.Object{cm, otherCM}, Parallelism(2))).To(Succeed())
+		})
+
+		It("should make DeleteMultiple report the first error after processing all objects", func() {
+			someErr := fmt.Errorf("some error")
+			c.EXPECT().Delete(ctx, cm).Return(someErr)
+			c.EXPECT().Delete(ctx, otherCM)
+
+			err := DeleteMultiple(ctx, c, []client.Object{cm, otherCM}, Parallelism(2))
+			Expect(err).To(MatchError("error deleting object default/my-cm: some error"))
+			Expect(errors.Is(err, someErr)).To(BeTrue())
+		})
+	})
+})
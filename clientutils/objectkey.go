@@ -14,7 +14,15 @@
 
 package clientutils
 
-import "sigs.k8s.io/controller-runtime/pkg/client"
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
 
 // ObjectKeySet set is a set of client.ObjectKey.
 type ObjectKeySet map[client.ObjectKey]struct{}
@@ -52,3 +60,157 @@ func NewObjectKeySet(items ...client.ObjectKey) ObjectKeySet {
 	s.Insert(items...)
 	return s
 }
+
+// Clone returns a copy of the ObjectKeySet.
+func (s ObjectKeySet) Clone() ObjectKeySet {
+	return setClone(s)
+}
+
+// Union returns a new ObjectKeySet containing the items of both sets.
+func (s ObjectKeySet) Union(s2 ObjectKeySet) ObjectKeySet {
+	return setUnion(s, s2)
+}
+
+// Intersection returns a new ObjectKeySet containing the items present in both sets.
+func (s ObjectKeySet) Intersection(s2 ObjectKeySet) ObjectKeySet {
+	return setIntersection(s, s2)
+}
+
+// Difference returns a new ObjectKeySet containing the items of s that are not in s2.
+func (s ObjectKeySet) Difference(s2 ObjectKeySet) ObjectKeySet {
+	return setDifference(s, s2)
+}
+
+// SymmetricDifference returns a new ObjectKeySet containing the items that are in exactly one of the sets.
+func (s ObjectKeySet) SymmetricDifference(s2 ObjectKeySet) ObjectKeySet {
+	return setSymmetricDifference(s, s2)
+}
+
+// IsSuperset reports whether s contains all items of s2.
+func (s ObjectKeySet) IsSuperset(s2 ObjectKeySet) bool {
+	return setIsSuperset(s, s2)
+}
+
+// Equal reports whether both sets contain the same items.
+func (s ObjectKeySet) Equal(s2 ObjectKeySet) bool {
+	return setEqual(s, s2)
+}
+
+// UnsortedList returns the items of the ObjectKeySet in arbitrary order.
+func (s ObjectKeySet) UnsortedList() []client.ObjectKey {
+	return setUnsortedList(s, setKey[client.ObjectKey])
+}
+
+// List returns the items of the ObjectKeySet sorted by namespace and name.
+func (s ObjectKeySet) List() []client.ObjectKey {
+	return setList(s, lessObjectKey, setKey[client.ObjectKey])
+}
+
+func lessObjectKey(a, b client.ObjectKey) bool {
+	if a.Namespace != b.Namespace {
+		return a.Namespace < b.Namespace
+	}
+	return a.Name < b.Name
+}
+
+// objectKeyEntry is the serialized form of a client.ObjectKey.
+type objectKeyEntry struct {
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+// MarshalJSON implements json.Marshaler.
+// The ObjectKeySet is encoded as a sorted list of objects with namespace and name.
+func (s ObjectKeySet) MarshalJSON() ([]byte, error) {
+	entries := make([]objectKeyEntry, 0, len(s))
+	for _, item := range s.List() {
+		entries = append(entries, objectKeyEntry{Namespace: item.Namespace, Name: item.Name})
+	}
+	return json.Marshal(entries)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *ObjectKeySet) UnmarshalJSON(data []byte) error {
+	var entries []objectKeyEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+
+	res := make(ObjectKeySet, len(entries))
+	for _, entry := range entries {
+		res.Insert(client.ObjectKey{Namespace: entry.Namespace, Name: entry.Name})
+	}
+	*s = res
+	return nil
+}
+
+// FormatObjectKey formats the given client.ObjectKey in its canonical text encoding 'namespace/name'.
+// For cluster-scoped objects, the namespace is empty, resulting in '/name'.
+func FormatObjectKey(key client.ObjectKey) string {
+	return key.Namespace + "/" + key.Name
+}
+
+// ParseObjectKey parses a client.ObjectKey from its text encoding as returned by FormatObjectKey.
+// For convenience, a value without '/' is parsed as the name of a cluster-scoped object.
+func ParseObjectKey(s string) (client.ObjectKey, error) {
+	var key client.ObjectKey
+	switch parts := strings.Split(s, "/"); len(parts) {
+	case 1:
+		key.Name = parts[0]
+	case 2:
+		key.Namespace, key.Name = parts[0], parts[1]
+	default:
+		return client.ObjectKey{}, fmt.Errorf("invalid object key %q: expected [namespace/]name", s)
+	}
+	if key.Name == "" {
+		return client.ObjectKey{}, fmt.Errorf("invalid object key %q: name must not be empty", s)
+	}
+	return key, nil
+}
+
+// ObjectKeyValue is a client.ObjectKey that uses the text encoding of FormatObjectKey.
+//
+// It implements encoding.TextMarshaler, encoding.TextUnmarshaler and pflag.Value, so it can be used
+// in JSON, annotations and as command line flag.
+type ObjectKeyValue client.ObjectKey
+
+var (
+	_ encoding.TextMarshaler   = ObjectKeyValue{}
+	_ encoding.TextUnmarshaler = (*ObjectKeyValue)(nil)
+	_ pflag.Value              = (*ObjectKeyValue)(nil)
+)
+
+// NewObjectKeyValue returns an *ObjectKeyValue that reads from and writes to the given client.ObjectKey.
+func NewObjectKeyValue(p *client.ObjectKey) *ObjectKeyValue {
+	return (*ObjectKeyValue)(p)
+}
+
+// String implements fmt.Stringer and pflag.Value.
+func (v ObjectKeyValue) String() string {
+	return FormatObjectKey(client.ObjectKey(v))
+}
+
+// Set implements pflag.Value.
+func (v *ObjectKeyValue) Set(s string) error {
+	key, err := ParseObjectKey(s)
+	if err != nil {
+		return err
+	}
+	*v = ObjectKeyValue(key)
+	return nil
+}
+
+// Type implements pflag.Value.
+func (v *ObjectKeyValue) Type() string {
+	return "objectKey"
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (v ObjectKeyValue) MarshalText() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (v *ObjectKeyValue) UnmarshalText(data []byte) error {
+	return v.Set(string(data))
+}
@@ -15,9 +15,12 @@
 package clientutils_test
 
 import (
+	"encoding/json"
+
 	. "github.com/ironcore-dev/controller-utils/clientutils"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/spf13/pflag"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -121,5 +124,116 @@ var _ = Describe("ObjectKey", func() {
 				Expect(NewObjectKeySet().Len()).To(Equal(0))
 			})
 		})
+
+		Describe("Union", func() {
+			It("should return the items of both sets", func() {
+				Expect(NewObjectKeySet(k1, k2).Union(NewObjectKeySet(k2, k3))).To(Equal(NewObjectKeySet(k1, k2, k3)))
+			})
+		})
+
+		Describe("Intersection", func() {
+			It("should return the items present in both sets", func() {
+				Expect(NewObjectKeySet(k1, k2).Intersection(NewObjectKeySet(k2, k3))).To(Equal(NewObjectKeySet(k2)))
+			})
+		})
+
+		Describe("Difference", func() {
+			It("should return the items only present in the first set", func() {
+				Expect(NewObjectKeySet(k1, k2).Difference(NewObjectKeySet(k2, k3))).To(Equal(NewObjectKeySet(k1)))
+			})
+		})
+
+		Describe("SymmetricDifference", func() {
+			It("should return the items present in exactly one of the sets", func() {
+				Expect(NewObjectKeySet(k1, k2).SymmetricDifference(NewObjectKeySet(k2, k3))).To(Equal(NewObjectKeySet(k1, k3)))
+			})
+		})
+
+		Describe("IsSuperset", func() {
+			It("should return whether the set contains all items of the other set", func() {
+				Expect(NewObjectKeySet(k1, k2).IsSuperset(NewObjectKeySet(k1))).To(BeTrue())
+				Expect(NewObjectKeySet(k1, k2).IsSuperset(nil)).To(BeTrue())
+				Expect(NewObjectKeySet(k1).IsSuperset(NewObjectKeySet(k1, k2))).To(BeFalse())
+			})
+		})
+
+		Describe("Equal", func() {
+			It("should return whether both sets contain the same items", func() {
+				Expect(NewObjectKeySet(k1, k2).Equal(NewObjectKeySet(k2, k1))).To(BeTrue())
+				Expect(NewObjectKeySet().Equal(nil)).To(BeTrue())
+				Expect(NewObjectKeySet(k1, k2).Equal(NewObjectKeySet(k1, k3))).To(BeFalse())
+			})
+		})
+
+		Describe("List", func() {
+			It("should return the items sorted by namespace and name", func() {
+				Expect(NewObjectKeySet(k1, k2, k3, k4, k5, k6).List()).To(Equal([]client.ObjectKey{k5, k6, k2, k1, k4, k3}))
+			})
+		})
+
+		Describe("UnsortedList", func() {
+			It("should return all items", func() {
+				Expect(NewObjectKeySet(k1, k2, k5).UnsortedList()).To(ConsistOf(k1, k2, k5))
+			})
+		})
+
+		Describe("JSON", func() {
+			It("should marshal and unmarshal the set", func() {
+				data, err := json.Marshal(NewObjectKeySet(k1, k5))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(data).To(MatchJSON(`[{"name":"cluster1"},{"namespace":"n1","name":"foo"}]`))
+
+				var s ObjectKeySet
+				Expect(json.Unmarshal(data, &s)).To(Succeed())
+				Expect(s).To(Equal(NewObjectKeySet(k1, k5)))
+			})
+		})
+	})
+
+	Describe("FormatObjectKey", func() {
+		It("should format the object key", func() {
+			Expect(FormatObjectKey(client.ObjectKey{Namespace: "foo", Name: "bar"})).To(Equal("foo/bar"))
+			Expect(FormatObjectKey(client.ObjectKey{Name: "bar"})).To(Equal("/bar"))
+		})
+	})
+
+	Describe("ParseObjectKey", func() {
+		It("should parse namespaced and cluster-scoped object keys", func() {
+			Expect(ParseObjectKey("foo/bar")).To(Equal(client.ObjectKey{Namespace: "foo", Name: "bar"}))
+			Expect(ParseObjectKey("/bar")).To(Equal(client.ObjectKey{Name: "bar"}))
+			Expect(ParseObjectKey("bar")).To(Equal(client.ObjectKey{Name: "bar"}))
+		})
+
+		It("should error on invalid object keys", func() {
+			_, err := ParseObjectKey("foo/bar/baz")
+			Expect(err).To(HaveOccurred())
+			_, err = ParseObjectKey("foo/")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("ObjectKeyValue", func() {
+		It("should round-trip via JSON", func() {
+			type config struct {
+				Key ObjectKeyValue `json:"key"`
+			}
+			data, err := json.Marshal(config{Key: ObjectKeyValue{Namespace: "foo", Name: "bar"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data).To(MatchJSON(`{"key":"foo/bar"}`))
+
+			var c config
+			Expect(json.Unmarshal(data, &c)).To(Succeed())
+			Expect(c.Key).To(Equal(ObjectKeyValue{Namespace: "foo", Name: "bar"}))
+		})
+
+		It("should be usable as flag", func() {
+			var key client.ObjectKey
+			fs := pflag.NewFlagSet("", pflag.ContinueOnError)
+			fs.Var(NewObjectKeyValue(&key), "key", "")
+
+			Expect(fs.Parse([]string{"--key=foo/bar"})).To(Succeed())
+			Expect(key).To(Equal(client.ObjectKey{Namespace: "foo", Name: "bar"}))
+			Expect(fs.Parse([]string{"--key=foo/bar/baz"})).NotTo(Succeed())
+		})
 	})
 })
@@ -15,6 +15,12 @@
 package clientutils
 
 import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/pflag"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -27,6 +33,97 @@ type ObjectRef struct {
 	Key       client.ObjectKey
 }
 
+var (
+	_ encoding.TextMarshaler   = ObjectRef{}
+	_ encoding.TextUnmarshaler = (*ObjectRef)(nil)
+	_ pflag.Value              = (*ObjectRef)(nil)
+)
+
+// ParseObjectRef parses an ObjectRef from its text encoding 'group/Kind/namespace/name' as returned by
+// ObjectRef.String. Group and namespace may be empty for the core group respectively cluster-scoped objects.
+func ParseObjectRef(s string) (ObjectRef, error) {
+	parts := strings.Split(s, "/")
+	if len(parts) != 4 {
+		return ObjectRef{}, fmt.Errorf("invalid object reference %q: expected group/Kind/namespace/name", s)
+	}
+
+	ref := ObjectRef{
+		GroupKind: schema.GroupKind{Group: parts[0], Kind: parts[1]},
+		Key:       client.ObjectKey{Namespace: parts[2], Name: parts[3]},
+	}
+	if ref.GroupKind.Kind == "" {
+		return ObjectRef{}, fmt.Errorf("invalid object reference %q: kind must not be empty", s)
+	}
+	if ref.Key.Name == "" {
+		return ObjectRef{}, fmt.Errorf("invalid object reference %q: name must not be empty", s)
+	}
+	return ref, nil
+}
+
+// String returns the canonical text encoding 'group/Kind/namespace/name' of the ObjectRef.
+// The zero ObjectRef is encoded as empty string.
+// It implements fmt.Stringer and pflag.Value.
+func (r ObjectRef) String() string {
+	if r == (ObjectRef{}) {
+		return ""
+	}
+	return r.GroupKind.Group + "/" + r.GroupKind.Kind + "/" + FormatObjectKey(r.Key)
+}
+
+// Set implements pflag.Value.
+func (r *ObjectRef) Set(s string) error {
+	ref, err := ParseObjectRef(s)
+	if err != nil {
+		return err
+	}
+	*r = ref
+	return nil
+}
+
+// Type implements pflag.Value.
+func (r *ObjectRef) Type() string {
+	return "objectRef"
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (r ObjectRef) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+// The empty string is decoded as the zero ObjectRef.
+func (r *ObjectRef) UnmarshalText(data []byte) error {
+	if len(data) == 0 {
+		*r = ObjectRef{}
+		return nil
+	}
+	return r.Set(string(data))
+}
+
+// objectRefEntry is the structured serialized form of an ObjectRef, as used in inventories.
+type objectRefEntry struct {
+	Group     string `json:"group,omitempty"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+func newObjectRefEntry(ref ObjectRef) objectRefEntry {
+	return objectRefEntry{
+		Group:     ref.GroupKind.Group,
+		Kind:      ref.GroupKind.Kind,
+		Namespace: ref.Key.Namespace,
+		Name:      ref.Key.Name,
+	}
+}
+
+func (e objectRefEntry) objectRef() ObjectRef {
+	return ObjectRef{
+		GroupKind: schema.GroupKind{Group: e.Group, Kind: e.Kind},
+		Key:       client.ObjectKey{Namespace: e.Namespace, Name: e.Name},
+	}
+}
+
 // ObjectRefFromObject creates a new ObjectRef from the given client.Object.
 func ObjectRefFromObject(scheme *runtime.Scheme, obj client.Object) (ObjectRef, error) {
 	gvk, err := apiutil.GVKForObject(obj, scheme)
@@ -109,6 +206,87 @@ func (s ObjectRefSet) Len() int {
 	return len(s)
 }
 
+// Clone returns a copy of the set.
+func (s ObjectRefSet) Clone() ObjectRefSet {
+	return setClone(s)
+}
+
+// Union returns a new set containing the items of both sets.
+func (s ObjectRefSet) Union(s2 ObjectRefSet) ObjectRefSet {
+	return setUnion(s, s2)
+}
+
+// Intersection returns a new set containing the items present in both sets.
+func (s ObjectRefSet) Intersection(s2 ObjectRefSet) ObjectRefSet {
+	return setIntersection(s, s2)
+}
+
+// Difference returns a new set containing the items of s that are not in s2.
+func (s ObjectRefSet) Difference(s2 ObjectRefSet) ObjectRefSet {
+	return setDifference(s, s2)
+}
+
+// SymmetricDifference returns a new set containing the items that are in exactly one of the sets.
+func (s ObjectRefSet) SymmetricDifference(s2 ObjectRefSet) ObjectRefSet {
+	return setSymmetricDifference(s, s2)
+}
+
+// IsSuperset reports whether s contains all items of s2.
+func (s ObjectRefSet) IsSuperset(s2 ObjectRefSet) bool {
+	return setIsSuperset(s, s2)
+}
+
+// Equal reports whether both sets contain the same items.
+func (s ObjectRefSet) Equal(s2 ObjectRefSet) bool {
+	return setEqual(s, s2)
+}
+
+// UnsortedList returns the items of the set in arbitrary order.
+func (s ObjectRefSet) UnsortedList() []ObjectRef {
+	return setUnsortedList(s, setKey[ObjectRef])
+}
+
+// List returns the items of the set sorted by group, kind, namespace and name.
+func (s ObjectRefSet) List() []ObjectRef {
+	return setList(s, lessObjectRef, setKey[ObjectRef])
+}
+
+func lessObjectRef(a, b ObjectRef) bool {
+	switch {
+	case a.GroupKind.Group != b.GroupKind.Group:
+		return a.GroupKind.Group < b.GroupKind.Group
+	case a.GroupKind.Kind != b.GroupKind.Kind:
+		return a.GroupKind.Kind < b.GroupKind.Kind
+	default:
+		return lessObjectKey(a.Key, b.Key)
+	}
+}
+
+// MarshalJSON implements json.Marshaler.
+// The set is encoded as a sorted list of objects with group, kind, namespace and name.
+func (s ObjectRefSet) MarshalJSON() ([]byte, error) {
+	entries := make([]objectRefEntry, 0, len(s))
+	for _, ref := range s.List() {
+		entries = append(entries, newObjectRefEntry(ref))
+	}
+	return json.Marshal(entries)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *ObjectRefSet) UnmarshalJSON(data []byte) error {
+	var entries []objectRefEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+
+	res := make(ObjectRefSet, len(entries))
+	for _, entry := range entries {
+		res.Insert(entry.objectRef())
+	}
+	*s = res
+	return nil
+}
+
 // NewObjectRefSet creates a new ObjectRefSet with the given set.
 func NewObjectRefSet(items ...ObjectRef) ObjectRefSet {
 	s := make(ObjectRefSet)
@@ -15,8 +15,11 @@
 package clientutils
 
 import (
+	"encoding/json"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/spf13/pflag"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -132,6 +135,68 @@ var _ = Describe("ObjectRef", func() {
 		})
 	})
 
+	Describe("Text encoding", func() {
+		It("should format the reference", func() {
+			Expect(cmRef.String()).To(Equal("/ConfigMap/default/my-cm"))
+			Expect(ObjectRef{
+				GroupKind: schema.GroupKind{Group: "apps", Kind: "Deployment"},
+				Key:       client.ObjectKey{Namespace: "default", Name: "my-deploy"},
+			}.String()).To(Equal("apps/Deployment/default/my-deploy"))
+			Expect(ObjectRef{
+				GroupKind: schema.GroupKind{Group: "rbac.authorization.k8s.io", Kind: "ClusterRole"},
+				Key:       client.ObjectKey{Name: "my-role"},
+			}.String()).To(Equal("rbac.authorization.k8s.io/ClusterRole//my-role"))
+		})
+
+		It("should parse a formatted reference", func() {
+			Expect(ParseObjectRef(cmRef.String())).To(Equal(cmRef))
+			Expect(ParseObjectRef("rbac.authorization.k8s.io/ClusterRole//my-role")).To(Equal(ObjectRef{
+				GroupKind: schema.GroupKind{Group: "rbac.authorization.k8s.io", Kind: "ClusterRole"},
+				Key:       client.ObjectKey{Name: "my-role"},
+			}))
+		})
+
+		It("should error parsing invalid references", func() {
+			for _, s := range []string{"", "ConfigMap/default/my-cm", "//default/my-cm", "/ConfigMap/default/", "a/b/c/d/e"} {
+				_, err := ParseObjectRef(s)
+				Expect(err).To(HaveOccurred(), "expected error parsing %q", s)
+			}
+		})
+
+		It("should round-trip via JSON", func() {
+			data, err := json.Marshal(map[string]ObjectRef{"ref": podRef})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data).To(MatchJSON(`{"ref":"/Pod/default/my-pod"}`))
+
+			var m map[string]ObjectRef
+			Expect(json.Unmarshal(data, &m)).To(Succeed())
+			Expect(m).To(HaveKeyWithValue("ref", podRef))
+		})
+
+		It("should round-trip the zero value via JSON", func() {
+			type withRef struct {
+				Ref ObjectRef `json:"ref"`
+			}
+
+			data, err := json.Marshal(withRef{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data).To(MatchJSON(`{"ref":""}`))
+
+			v := withRef{Ref: podRef}
+			Expect(json.Unmarshal(data, &v)).To(Succeed())
+			Expect(v).To(Equal(withRef{}))
+		})
+
+		It("should be usable as flag", func() {
+			var ref ObjectRef
+			fs := pflag.NewFlagSet("", pflag.ContinueOnError)
+			fs.Var(&ref, "ref", "")
+
+			Expect(fs.Parse([]string{"--ref=/Pod/default/my-pod"})).To(Succeed())
+			Expect(ref).To(Equal(podRef))
+		})
+	})
+
 	Context("ObjectRefSet", func() {
 		Describe("NewObjectRefSet", func() {
 			It("should create a new object ref set with the given items", func() {
@@ -183,6 +248,63 @@ var _ = Describe("ObjectRef", func() {
 			})
 		})
 
+		Describe("Set algebra", func() {
+			var secretRef ObjectRef
+			BeforeEach(func() {
+				secretRef = ObjectRef{
+					GroupKind: schema.GroupKind{Kind: "Secret"},
+					Key:       client.ObjectKey{Namespace: namespace, Name: "my-secret"},
+				}
+			})
+
+			It("should compute union, intersection and differences", func() {
+				s1 := NewObjectRefSet(cmRef, podRef)
+				s2 := NewObjectRefSet(podRef, secretRef)
+
+				Expect(s1.Union(s2)).To(Equal(NewObjectRefSet(cmRef, podRef, secretRef)))
+				Expect(s1.Intersection(s2)).To(Equal(NewObjectRefSet(podRef)))
+				Expect(s1.Difference(s2)).To(Equal(NewObjectRefSet(cmRef)))
+				Expect(s1.SymmetricDifference(s2)).To(Equal(NewObjectRefSet(cmRef, secretRef)))
+			})
+
+			It("should not modify the operands", func() {
+				s1 := NewObjectRefSet(cmRef)
+				s2 := NewObjectRefSet(podRef)
+				_ = s1.Union(s2)
+				Expect(s1).To(Equal(NewObjectRefSet(cmRef)))
+				Expect(s2).To(Equal(NewObjectRefSet(podRef)))
+			})
+
+			It("should compare sets", func() {
+				Expect(NewObjectRefSet(cmRef, podRef).IsSuperset(NewObjectRefSet(podRef))).To(BeTrue())
+				Expect(NewObjectRefSet(podRef).IsSuperset(NewObjectRefSet(cmRef, podRef))).To(BeFalse())
+				Expect(NewObjectRefSet(cmRef, podRef).Equal(NewObjectRefSet(podRef, cmRef))).To(BeTrue())
+				Expect(NewObjectRefSet(cmRef).Equal(NewObjectRefSet(podRef))).To(BeFalse())
+			})
+		})
+
+		Describe("List", func() {
+			It("should return the items sorted by group, kind, namespace and name", func() {
+				otherCMRef := ObjectRef{GroupKind: cmGK, Key: client.ObjectKey{Namespace: "a", Name: "z"}}
+				Expect(NewObjectRefSet(podRef, cmRef, otherCMRef).List()).To(Equal([]ObjectRef{otherCMRef, cmRef, podRef}))
+			})
+		})
+
+		Describe("JSON", func() {
+			It("should marshal and unmarshal the set", func() {
+				data, err := json.Marshal(NewObjectRefSet(podRef, cmRef))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(data).To(MatchJSON(`[
+					{"kind":"ConfigMap","namespace":"default","name":"my-cm"},
+					{"kind":"Pod","namespace":"default","name":"my-pod"}
+				]`))
+
+				var s ObjectRefSet
+				Expect(json.Unmarshal(data, &s)).To(Succeed())
+				Expect(s).To(Equal(NewObjectRefSet(podRef, cmRef)))
+			})
+		})
+
 		Describe("ObjectRefSetReferencesObject", func() {
 			It("should report whether the object is referenced by the set", func() {
 				s := NewObjectRefSet(cmRef)
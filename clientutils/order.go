@@ -0,0 +1,276 @@
+// Copyright 2023 IronCore authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientutils
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// Kind priorities used by DefaultKindPriorities.
+// Objects with a lower priority are created before objects with a higher priority.
+const (
+	KindPriorityNamespace = iota * 100
+	KindPriorityCustomResourceDefinition
+	KindPriorityRBAC
+	KindPriorityConfig
+	KindPriorityWorkload
+	KindPriorityOther
+	KindPriorityWebhook
+)
+
+// DefaultEstablishTimeout is the default duration to wait for a CustomResourceDefinition to become established.
+const DefaultEstablishTimeout = 1 * time.Minute
+
+const establishPollInterval = 500 * time.Millisecond
+
+var crdGroupKind = schema.GroupKind{Group: apiextensionsv1.GroupName, Kind: "CustomResourceDefinition"}
+
+// KindPriorities maps schema.GroupKind to priorities.
+// Objects with a lower priority are created before objects with a higher priority.
+type KindPriorities map[schema.GroupKind]int
+
+// Priority returns the priority for the given schema.GroupKind.
+// If the schema.GroupKind is not known, KindPriorityOther is returned.
+func (p KindPriorities) Priority(gk schema.GroupKind) int {
+	if priority, ok := p[gk]; ok {
+		return priority
+	}
+	return KindPriorityOther
+}
+
+// DefaultKindPriorities orders Namespaces first, followed by CustomResourceDefinitions, RBAC objects,
+// ConfigMaps and Secrets, and workloads. Objects of any other kind follow, and webhook configurations come last.
+var DefaultKindPriorities = KindPriorities{
+	{Group: corev1.GroupName, Kind: "Namespace"}: KindPriorityNamespace,
+
+	crdGroupKind: KindPriorityCustomResourceDefinition,
+
+	{Group: corev1.GroupName, Kind: "ServiceAccount"}:     KindPriorityRBAC,
+	{Group: rbacv1.GroupName, Kind: "ClusterRole"}:        KindPriorityRBAC,
+	{Group: rbacv1.GroupName, Kind: "ClusterRoleBinding"}: KindPriorityRBAC,
+	{Group: rbacv1.GroupName, Kind: "Role"}:               KindPriorityRBAC,
+	{Group: rbacv1.GroupName, Kind: "RoleBinding"}:        KindPriorityRBAC,
+
+	{Group: corev1.GroupName, Kind: "ConfigMap"}: KindPriorityConfig,
+	{Group: corev1.GroupName, Kind: "Secret"}:    KindPriorityConfig,
+
+	{Group: corev1.GroupName, Kind: "Service"}:     KindPriorityWorkload,
+	{Group: corev1.GroupName, Kind: "Pod"}:         KindPriorityWorkload,
+	{Group: appsv1.GroupName, Kind: "Deployment"}:  KindPriorityWorkload,
+	{Group: appsv1.GroupName, Kind: "StatefulSet"}: KindPriorityWorkload,
+	{Group: appsv1.GroupName, Kind: "DaemonSet"}:   KindPriorityWorkload,
+	{Group: appsv1.GroupName, Kind: "ReplicaSet"}:  KindPriorityWorkload,
+	{Group: batchv1.GroupName, Kind: "Job"}:        KindPriorityWorkload,
+	{Group: batchv1.GroupName, Kind: "CronJob"}:    KindPriorityWorkload,
+
+	{Group: admissionregistrationv1.GroupName, Kind: "MutatingWebhookConfiguration"}:   KindPriorityWebhook,
+	{Group: admissionregistrationv1.GroupName, Kind: "ValidatingWebhookConfiguration"}: KindPriorityWebhook,
+}
+
+// OrderByKind makes a multi-object operation process objects ordered by the priority of their kind.
+// Objects of the same priority keep their relative order. Deleting objects happens in reverse order.
+//
+// When creating or patching, the operation waits for any CustomResourceDefinition to become established
+// before processing objects of a higher priority.
+type OrderByKind struct {
+	// Priorities are the priorities to order by. If unset, DefaultKindPriorities is used.
+	Priorities KindPriorities
+	// EstablishTimeout is the maximum duration to wait for a CustomResourceDefinition to become established.
+	// If unset, DefaultEstablishTimeout is used.
+	EstablishTimeout time.Duration
+}
+
+// ApplyToMultiple implements MultipleOption.
+func (o OrderByKind) ApplyToMultiple(opts *MultipleOptions) {
+	priorities := o.Priorities
+	if priorities == nil {
+		priorities = DefaultKindPriorities
+	}
+	opts.KindPriorities = priorities
+	opts.EstablishTimeout = o.EstablishTimeout
+}
+
+// ApplyToCreate implements client.CreateOption.
+func (o OrderByKind) ApplyToCreate(*client.CreateOptions) {}
+
+// ApplyToPatch implements client.PatchOption.
+func (o OrderByKind) ApplyToPatch(*client.PatchOptions) {}
+
+// ApplyToDelete implements client.DeleteOption.
+func (o OrderByKind) ApplyToDelete(*client.DeleteOptions) {}
+
+// SortObjectsByKind returns the given objects sorted by the priority of their kind.
+// Objects of the same priority keep their relative order.
+func SortObjectsByKind(scheme *runtime.Scheme, objs []client.Object, priorities KindPriorities) ([]client.Object, error) {
+	tiers, err := kindTiers(scheme, objs, priorities, false)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]client.Object, 0, len(objs))
+	for _, tier := range tiers {
+		for _, i := range tier {
+			res = append(res, objs[i])
+		}
+	}
+	return res, nil
+}
+
+// kindTiers returns the indices of the given objects, grouped by the priority of their kind.
+// If reverse is set, the tiers and the indices within them are in reverse order.
+func kindTiers(scheme *runtime.Scheme, objs []client.Object, priorities KindPriorities, reverse bool) ([][]int, error) {
+	var (
+		indices          = make([]int, len(objs))
+		objectPriorities = make([]int, len(objs))
+	)
+	for i, obj := range objs {
+		gvk, err := apiutil.GVKForObject(obj, scheme)
+		if err != nil {
+			return nil, fmt.Errorf("error getting kind of object %s: %w", client.ObjectKeyFromObject(obj), err)
+		}
+
+		indices[i] = i
+		objectPriorities[i] = priorities.Priority(gvk.GroupKind())
+	}
+
+	sort.SliceStable(indices, func(i, j int) bool {
+		return objectPriorities[indices[i]] < objectPriorities[indices[j]]
+	})
+	if reverse {
+		for i, j := 0, len(indices)-1; i < j; i, j = i+1, j-1 {
+			indices[i], indices[j] = indices[j], indices[i]
+		}
+	}
+
+	var tiers [][]int
+	for n, i := range indices {
+		if n == 0 || objectPriorities[i] != objectPriorities[indices[n-1]] {
+			tiers = append(tiers, nil)
+		}
+		tiers[len(tiers)-1] = append(tiers[len(tiers)-1], i)
+	}
+	return tiers, nil
+}
+
+// processingTiers returns the indices of the given objects in the tiers they should be processed in.
+// Without kind priorities, all objects form a single tier in the given order.
+func processingTiers(c client.Client, o *MultipleOptions, objs []client.Object, reverse bool) ([][]int, error) {
+	if o.KindPriorities == nil {
+		indices := make([]int, len(objs))
+		for i := range indices {
+			indices[i] = i
+		}
+		return [][]int{indices}, nil
+	}
+	return kindTiers(c.Scheme(), objs, o.KindPriorities, reverse)
+}
+
+// isCustomResourceDefinition reports whether the given object is a CustomResourceDefinition.
+func isCustomResourceDefinition(scheme *runtime.Scheme, obj client.Object) bool {
+	gvk, err := apiutil.GVKForObject(obj, scheme)
+	return err == nil && gvk.GroupKind() == crdGroupKind
+}
+
+// waitForEstablished waits until the CustomResourceDefinition with the given name is established.
+func waitForEstablished(ctx context.Context, c client.Client, o *MultipleOptions, name string) error {
+	timeout := o.EstablishTimeout
+	if timeout <= 0 {
+		timeout = DefaultEstablishTimeout
+	}
+
+	crd := &unstructured.Unstructured{}
+	crd.SetGroupVersionKind(apiextensionsv1.SchemeGroupVersion.WithKind(crdGroupKind.Kind))
+	if err := wait.PollUntilContextTimeout(ctx, establishPollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		if err := c.Get(ctx, client.ObjectKey{Name: name}, crd); err != nil {
+			return false, err
+		}
+		return isEstablished(crd)
+	}); err != nil {
+		return fmt.Errorf("error waiting for custom resource definition %s to be established: %w", name, err)
+	}
+	return nil
+}
+
+func isEstablished(crd *unstructured.Unstructured) (bool, error) {
+	conditions, _, err := unstructured.NestedSlice(crd.Object, "status", "conditions")
+	if err != nil {
+		return false, err
+	}
+
+	for _, condition := range conditions {
+		condition, ok := condition.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if condition["type"] == string(apiextensionsv1.Established) {
+			return condition["status"] == string(apiextensionsv1.ConditionTrue), nil
+		}
+	}
+	return false, nil
+}
+
+// waitForEstablishedInTier waits for all CustomResourceDefinitions in the given tier to be established.
+// If the objects are not ordered by kind, it does nothing.
+func waitForEstablishedInTier(ctx context.Context, c client.Client, o *MultipleOptions, objs []client.Object, tier []int) error {
+	if o.KindPriorities == nil {
+		return nil
+	}
+
+	scheme := c.Scheme()
+	for _, i := range tier {
+		if !isCustomResourceDefinition(scheme, objs[i]) {
+			continue
+		}
+		if err := waitForEstablished(ctx, c, o, objs[i].GetName()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// establishTier waits for all successfully processed CustomResourceDefinitions in the given tier to be
+// established. If waiting fails, the error is reported as the outcome of the respective object.
+// If the objects are not ordered by kind, it does nothing.
+func establishTier(ctx context.Context, c client.Client, o *MultipleOptions, objs []client.Object, tier []int, results []ObjectResult) {
+	if o.KindPriorities == nil {
+		return
+	}
+
+	scheme := c.Scheme()
+	for _, i := range tier {
+		if results[i].Err != nil || !isCustomResourceDefinition(scheme, objs[i]) {
+			continue
+		}
+		if err := waitForEstablished(ctx, c, o, objs[i].GetName()); err != nil {
+			results[i].Err = err
+		}
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright 2023 IronCore authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientutils_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/ironcore-dev/controller-utils/clientutils"
+	mockclient "github.com/ironcore-dev/controller-utils/mock/controller-runtime/client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Order", func() {
+	var (
+		ctx  context.Context
+		ctrl *gomock.Controller
+
+		c *mockclient.MockClient
+
+		ns         *corev1.Namespace
+		crd        *unstructured.Unstructured
+		cr         *unstructured.Unstructured
+		cm         *corev1.ConfigMap
+		deployment *appsv1.Deployment
+		webhook    *admissionregistrationv1.ValidatingWebhookConfiguration
+	)
+	BeforeEach(func() {
+		ctx = context.Background()
+		ctrl = gomock.NewController(GinkgoT())
+
+		c = mockclient.NewMockClient(ctrl)
+		c.EXPECT().Scheme().Return(scheme.Scheme).AnyTimes()
+
+		ns = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "my-ns"}}
+		crd = &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "apiextensions.k8s.io/v1",
+				"kind":       "CustomResourceDefinition",
+				"metadata": map[string]interface{}{
+					"name": "foos.example.org",
+				},
+			},
+		}
+		cr = &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "example.org/v1",
+				"kind":       "Foo",
+				"metadata": map[string]interface{}{
+					"namespace": "my-ns",
+					"name":      "my-foo",
+				},
+			},
+		}
+		cm = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "my-ns", Name: "my-cm"}}
+		deployment = &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "my-ns", Name: "my-deployment"}}
+		webhook = &admissionregistrationv1.ValidatingWebhookConfiguration{ObjectMeta: metav1.ObjectMeta{Name: "my-webhook"}}
+	})
+
+	setEstablished := func(status string) func(context.Context, client.ObjectKey, client.Object, ...client.GetOption) error {
+		return func(_ context.Context, _ client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+			u := obj.(*unstructured.Unstructured)
+			return unstructured.SetNestedSlice(u.Object, []interface{}{
+				map[string]interface{}{
+					"type":   "Established",
+					"status": status,
+				},
+			}, "status", "conditions")
+		}
+	}
+
+	Describe("SortObjectsByKind", func() {
+		It("should sort the objects by the priority of their kind", func() {
+			objs, err := SortObjectsByKind(scheme.Scheme,
+				[]client.Object{webhook, cr, deployment, cm, crd, ns},
+				DefaultKindPriorities,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(objs).To(Equal([]client.Object{ns, crd, cm, deployment, cr, webhook}))
+		})
+
+		It("should keep the relative order of objects with the same priority", func() {
+			otherCM := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "my-ns", Name: "other-cm"}}
+			secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "my-ns", Name: "my-secret"}}
+
+			objs, err := SortObjectsByKind(scheme.Scheme,
+				[]client.Object{otherCM, ns, secret, cm},
+				DefaultKindPriorities,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(objs).To(Equal([]client.Object{ns, otherCM, secret, cm}))
+		})
+
+		It("should error if the kind of an object cannot be determined", func() {
+			_, err := SortObjectsByKind(scheme.Scheme, []client.Object{&unstructured.Unstructured{}}, DefaultKindPriorities)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("CreateMultiple", func() {
+		It("should create the objects in order, waiting for custom resource definitions to be established", func() {
+			gomock.InOrder(
+				c.EXPECT().Create(ctx, ns),
+				c.EXPECT().Create(ctx, crd),
+				c.EXPECT().Get(gomock.Any(), client.ObjectKey{Name: "foos.example.org"}, gomock.Any()).
+					DoAndReturn(setEstablished("True")),
+				c.EXPECT().Create(ctx, cm),
+				c.EXPECT().Create(ctx, cr),
+			)
+
+			Expect(CreateMultiple(ctx, c, []client.Object{cr, cm, crd, ns}, OrderByKind{})).To(Succeed())
+		})
+
+		It("should error if a custom resource definition does not become established", func() {
+			gomock.InOrder(
+				c.EXPECT().Create(ctx, crd),
+				c.EXPECT().Get(gomock.Any(), client.ObjectKey{Name: "foos.example.org"}, gomock.Any()).
+					DoAndReturn(setEstablished("False")).
+					AnyTimes(),
+			)
+
+			err := CreateMultiple(ctx, c, []client.Object{cr, crd}, OrderByKind{EstablishTimeout: 10 * time.Millisecond})
+			Expect(err).To(MatchError(ContainSubstring("error waiting for custom resource definition foos.example.org to be established")))
+		})
+
+		It("should report a custom resource definition that does not become established when continuing on error", func() {
+			gomock.InOrder(
+				c.EXPECT().Create(ctx, crd),
+				c.EXPECT().Get(gomock.Any(), client.ObjectKey{Name: "foos.example.org"}, gomock.Any()).
+					DoAndReturn(setEstablished("False")).
+					AnyTimes(),
+				c.EXPECT().Create(ctx, cr),
+			)
+
+			err := CreateMultiple(ctx, c, []client.Object{cr, crd}, OrderByKind{EstablishTimeout: 10 * time.Millisecond}, ContinueOnError)
+			var multiErr *MultipleError
+			Expect(errors.As(err, &multiErr)).To(BeTrue())
+			Expect(multiErr.Failed()).To(Equal([]client.Object{crd}))
+			Expect(multiErr.Succeeded).To(Equal([]client.Object{cr}))
+		})
+	})
+
+	Describe("PatchMultipleFromFile", func() {
+		It("should patch the objects from the file in order", func() {
+			gomock.InOrder(
+				c.EXPECT().Patch(ctx, gomock.Any(), client.Apply).Do(
+					func(_ context.Context, obj client.Object, _ client.Patch, _ ...client.PatchOption) {
+						Expect(obj.GetName()).To(Equal("my-secret"))
+					}),
+				c.EXPECT().Patch(ctx, gomock.Any(), client.Apply).Do(
+					func(_ context.Context, obj client.Object, _ client.Patch, _ ...client.PatchOption) {
+						Expect(obj.GetName()).To(Equal("my-configmap"))
+					}),
+			)
+
+			_, err := PatchMultipleFromFile(ctx, c, "../testdata/bases/objects.yaml", ApplyAll, OrderByKind{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("DeleteMultiple", func() {
+		It("should delete the objects in reverse order", func() {
+			gomock.InOrder(
+				c.EXPECT().Delete(ctx, webhook),
+				c.EXPECT().Delete(ctx, cr),
+				c.EXPECT().Delete(ctx, deployment),
+				c.EXPECT().Delete(ctx, cm),
+				c.EXPECT().Delete(ctx, crd),
+				c.EXPECT().Delete(ctx, ns),
+			)
+
+			Expect(DeleteMultiple(ctx, c, []client.Object{ns, crd, cm, deployment, cr, webhook}, OrderByKind{})).To(Succeed())
+		})
+	})
+
+	Describe("DeleteMultipleParallel", func() {
+		It("should delete the objects tier by tier in reverse order", func() {
+			gomock.InOrder(
+				c.EXPECT().Delete(ctx, deployment),
+				c.EXPECT().Delete(ctx, ns),
+			)
+
+			_, err := DeleteMultipleParallel(ctx, c, []client.Object{ns, deployment}, OrderByKind{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,255 @@
+// Copyright 2023 IronCore authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientutils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ironcore-dev/controller-utils/metautils"
+	"github.com/ironcore-dev/controller-utils/unstructuredutils"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// InventoryIDLabel is the label applied objects are marked with to identify the inventory they belong to.
+	InventoryIDLabel = "controller-utils.ironcore.dev/inventory-id"
+
+	// InventoryDataKey is the key of the ConfigMap / Secret data an inventory is recorded in.
+	InventoryDataKey = "inventory"
+)
+
+// Inventory is a set of applied objects that is recorded in a parent ConfigMap or Secret.
+type Inventory struct {
+	// Parent is the object to record the inventory in.
+	// It has to be a *corev1.ConfigMap or *corev1.Secret with namespace and name set.
+	// If the parent does not exist, it is created.
+	Parent client.Object
+	// ID identifies the inventory. It has to be a valid label value.
+	// If empty, the name of the parent is used.
+	ID string
+}
+
+func (i Inventory) id() string {
+	if i.ID != "" {
+		return i.ID
+	}
+	return i.Parent.GetName()
+}
+
+func encodeInventory(refs ObjectRefSet) ([]byte, error) {
+	return json.Marshal(refs)
+}
+
+func decodeInventory(data []byte) (ObjectRefSet, error) {
+	if len(data) == 0 {
+		return NewObjectRefSet(), nil
+	}
+
+	var refs ObjectRefSet
+	if err := json.Unmarshal(data, &refs); err != nil {
+		return nil, fmt.Errorf("error decoding inventory: %w", err)
+	}
+	return refs, nil
+}
+
+func inventoryData(parent client.Object) ([]byte, error) {
+	switch parent := parent.(type) {
+	case *corev1.ConfigMap:
+		return []byte(parent.Data[InventoryDataKey]), nil
+	case *corev1.Secret:
+		return parent.Data[InventoryDataKey], nil
+	default:
+		return nil, fmt.Errorf("unsupported inventory parent type %T", parent)
+	}
+}
+
+func setInventoryData(parent client.Object, data []byte) error {
+	switch parent := parent.(type) {
+	case *corev1.ConfigMap:
+		if parent.Data == nil {
+			parent.Data = make(map[string]string)
+		}
+		parent.Data[InventoryDataKey] = string(data)
+		return nil
+	case *corev1.Secret:
+		if parent.Data == nil {
+			parent.Data = make(map[string][]byte)
+		}
+		parent.Data[InventoryDataKey] = data
+		return nil
+	default:
+		return fmt.Errorf("unsupported inventory parent type %T", parent)
+	}
+}
+
+// GetInventory gets the parent of the given Inventory and returns the recorded object references.
+// If the parent does not exist, an empty ObjectRefSet is returned.
+func GetInventory(ctx context.Context, c client.Client, inventory Inventory) (ObjectRefSet, error) {
+	if err := c.Get(ctx, client.ObjectKeyFromObject(inventory.Parent), inventory.Parent); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("error getting inventory parent: %w", err)
+		}
+		return NewObjectRefSet(), nil
+	}
+
+	data, err := inventoryData(inventory.Parent)
+	if err != nil {
+		return nil, err
+	}
+	return decodeInventory(data)
+}
+
+func recordInventory(ctx context.Context, c client.Client, inventory Inventory, refs ObjectRefSet) error {
+	data, err := encodeInventory(refs)
+	if err != nil {
+		return err
+	}
+
+	if _, err := controllerutil.CreateOrPatch(ctx, c, inventory.Parent, func() error {
+		return setInventoryData(inventory.Parent, data)
+	}); err != nil {
+		return fmt.Errorf("error recording inventory: %w", err)
+	}
+	return nil
+}
+
+// getObjectMetadata gets the metadata of the object referenced by ref, using the client's RESTMapper to determine
+// the version to use. If the object does not exist, nil is returned.
+func getObjectMetadata(ctx context.Context, c client.Client, ref ObjectRef) (*metav1.PartialObjectMetadata, error) {
+	mapping, err := c.RESTMapper().RESTMapping(ref.GroupKind)
+	if err != nil {
+		return nil, fmt.Errorf("error getting mapping for %s: %w", ref.GroupKind, err)
+	}
+
+	obj := &metav1.PartialObjectMetadata{}
+	obj.SetGroupVersionKind(mapping.GroupVersionKind)
+	if err := c.Get(ctx, ref.Key, obj); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		return nil, nil
+	}
+	return obj, nil
+}
+
+// pruneObject deletes the object referenced by ref if it exists and still belongs to the inventory with the given id.
+func pruneObject(ctx context.Context, c client.Client, id string, ref ObjectRef) (bool, error) {
+	obj, err := getObjectMetadata(ctx, c, ref)
+	if err != nil || obj == nil {
+		return false, err
+	}
+
+	if obj.GetLabels()[InventoryIDLabel] != id {
+		return false, nil
+	}
+	return DeleteIfExists(ctx, c, obj)
+}
+
+// PatchMultipleWithPrune executes multiple PatchRequest like PatchMultiple and deletes all objects of the
+// previous inventory that are not part of the requests anymore.
+//
+// Every object is labeled with InventoryIDLabel before patching. Only objects still carrying the label
+// with the inventory ID are pruned. The label only reaches the server for patches computed from the object,
+// e.g. client.Apply, client.Merge or client.MergeFrom. Patches with raw data, e.g. a JSON patch created via
+// client.RawPatch, have to set the label themselves, otherwise the object is never pruned.
+//
+// Before patching, the references of the previous inventory and of the patched objects are recorded,
+// so objects created by a run failing later on are still tracked. After pruning, the references of the
+// patched objects are recorded as the new inventory. If patching any object fails, nothing is pruned.
+// The references of the pruned objects are returned.
+func PatchMultipleWithPrune(
+	ctx context.Context,
+	c client.Client,
+	inventory Inventory,
+	reqs []PatchRequest,
+	opts ...client.PatchOption,
+) ([]ObjectRef, error) {
+	id := inventory.id()
+
+	previous, err := GetInventory(ctx, c, inventory)
+	if err != nil {
+		return nil, err
+	}
+
+	objs := ObjectsFromPatchRequests(reqs)
+	current, err := ObjectRefSetFromObjects(c.Scheme(), objs)
+	if err != nil {
+		return nil, fmt.Errorf("error getting object references: %w", err)
+	}
+
+	if !previous.IsSuperset(current) {
+		if err := recordInventory(ctx, c, inventory, previous.Union(current)); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, obj := range objs {
+		metautils.SetLabel(obj, InventoryIDLabel, id)
+	}
+	if err := PatchMultiple(ctx, c, reqs, opts...); err != nil {
+		return nil, err
+	}
+
+	var pruned []ObjectRef
+	for _, ref := range previous.List() {
+		if current.Has(ref) {
+			continue
+		}
+
+		ok, err := pruneObject(ctx, c, id, ref)
+		if err != nil {
+			return pruned, fmt.Errorf("error pruning %s %s: %w", ref.GroupKind, ref.Key, err)
+		}
+		if ok {
+			pruned = append(pruned, ref)
+		}
+	}
+
+	if err := recordInventory(ctx, c, inventory, current); err != nil {
+		return pruned, err
+	}
+	return pruned, nil
+}
+
+// PatchMultipleFromFileWithPrune patches all objects from the given filename using the patch provider like
+// PatchMultipleFromFile and prunes all objects of the previous inventory that are not part of the file anymore.
+// See PatchMultipleWithPrune for more.
+func PatchMultipleFromFileWithPrune(
+	ctx context.Context,
+	c client.Client,
+	inventory Inventory,
+	filename string,
+	patchProvider PatchProvider,
+	opts ...client.PatchOption,
+) ([]unstructured.Unstructured, []ObjectRef, error) {
+	objs, err := unstructuredutils.ReadFile(filename)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading file: %w", err)
+	}
+
+	reqs := PatchRequestsFromObjectsAndProvider(unstructuredutils.UnstructuredSliceToObjectSliceNoCopy(objs), patchProvider)
+	pruned, err := PatchMultipleWithPrune(ctx, c, inventory, reqs, opts...)
+	if err != nil {
+		return nil, pruned, err
+	}
+	return objs, pruned, nil
+}
@@ -0,0 +1,199 @@
+// Copyright 2023 IronCore authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientutils_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	. "github.com/ironcore-dev/controller-utils/clientutils"
+	mockclient "github.com/ironcore-dev/controller-utils/mock/controller-runtime/client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Prune", func() {
+	const inventoryID = "my-inventory"
+
+	var (
+		ctx  context.Context
+		ctrl *gomock.Controller
+
+		c *mockclient.MockClient
+
+		parent    *corev1.ConfigMap
+		parentKey client.ObjectKey
+		inventory Inventory
+
+		cm        *corev1.ConfigMap
+		cmRef     ObjectRef
+		secret    *corev1.Secret
+		secretRef ObjectRef
+	)
+	BeforeEach(func() {
+		ctx = context.Background()
+		ctrl = gomock.NewController(GinkgoT())
+
+		mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{corev1.SchemeGroupVersion})
+		mapper.Add(corev1.SchemeGroupVersion.WithKind("Secret"), meta.RESTScopeNamespace)
+
+		c = mockclient.NewMockClient(ctrl)
+		c.EXPECT().Scheme().Return(scheme.Scheme).AnyTimes()
+		c.EXPECT().RESTMapper().Return(mapper).AnyTimes()
+
+		parent = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: corev1.NamespaceDefault,
+				Name:      "my-inventory-parent",
+			},
+		}
+		parentKey = client.ObjectKeyFromObject(parent)
+		inventory = Inventory{Parent: parent, ID: inventoryID}
+
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: corev1.NamespaceDefault,
+				Name:      "my-cm",
+			},
+		}
+		cmRef = ObjectRef{GroupKind: schema.GroupKind{Kind: "ConfigMap"}, Key: client.ObjectKeyFromObject(cm)}
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: corev1.NamespaceDefault,
+				Name:      "my-secret",
+			},
+		}
+		secretRef = ObjectRef{GroupKind: schema.GroupKind{Kind: "Secret"}, Key: client.ObjectKeyFromObject(secret)}
+	})
+
+	expectGetParent := func(data string) *gomock.Call {
+		return c.EXPECT().Get(gomock.Any(), parentKey, parent).DoAndReturn(
+			func(_ context.Context, _ client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+				if data == "" {
+					return apierrors.NewNotFound(corev1.Resource("configmaps"), parentKey.Name)
+				}
+				obj.(*corev1.ConfigMap).Data = map[string]string{InventoryDataKey: data}
+				return nil
+			})
+	}
+
+	Describe("GetInventory", func() {
+		It("should return an empty set if the parent does not exist", func() {
+			expectGetParent("")
+
+			refs, err := GetInventory(ctx, c, inventory)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(refs).To(BeEmpty())
+		})
+
+		It("should return the recorded references", func() {
+			expectGetParent(`[{"kind":"ConfigMap","namespace":"default","name":"my-cm"}]`)
+
+			refs, err := GetInventory(ctx, c, inventory)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(refs).To(Equal(NewObjectRefSet(cmRef)))
+		})
+	})
+
+	Describe("PatchMultipleWithPrune", func() {
+		It("should label the objects and record the inventory if there was none before", func() {
+			current := `[{"kind":"ConfigMap","namespace":"default","name":"my-cm"}]`
+			gomock.InOrder(
+				expectGetParent(""),
+				expectGetParent(""),
+				c.EXPECT().Create(ctx, parent).Do(
+					func(_ context.Context, obj client.Object, _ ...client.CreateOption) {
+						Expect(obj.(*corev1.ConfigMap).Data).To(HaveKeyWithValue(InventoryDataKey, current))
+					}),
+				c.EXPECT().Patch(ctx, cm, client.Apply).Do(
+					func(_ context.Context, obj client.Object, _ client.Patch, _ ...client.PatchOption) {
+						Expect(obj.GetLabels()).To(HaveKeyWithValue(InventoryIDLabel, inventoryID))
+					}),
+				expectGetParent(current),
+			)
+
+			pruned, err := PatchMultipleWithPrune(ctx, c, inventory, []PatchRequest{{Object: cm, Patch: client.Apply}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pruned).To(BeEmpty())
+		})
+
+		It("should prune objects of the previous inventory that are not present anymore", func() {
+			previous := `[{"kind":"ConfigMap","namespace":"default","name":"my-cm"},{"kind":"Secret","namespace":"default","name":"my-secret"}]`
+			gomock.InOrder(
+				expectGetParent(previous),
+				c.EXPECT().Patch(ctx, cm, client.Apply),
+				c.EXPECT().Get(ctx, secretRef.Key, gomock.AssignableToTypeOf(&metav1.PartialObjectMetadata{})).DoAndReturn(
+					func(_ context.Context, _ client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+						Expect(obj.GetObjectKind().GroupVersionKind()).To(Equal(corev1.SchemeGroupVersion.WithKind("Secret")))
+						obj.SetLabels(map[string]string{InventoryIDLabel: inventoryID})
+						return nil
+					}),
+				c.EXPECT().Delete(ctx, gomock.AssignableToTypeOf(&metav1.PartialObjectMetadata{})),
+				expectGetParent(previous),
+				c.EXPECT().Patch(ctx, parent, gomock.Any()),
+			)
+
+			pruned, err := PatchMultipleWithPrune(ctx, c, inventory, []PatchRequest{{Object: cm, Patch: client.Apply}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pruned).To(Equal([]ObjectRef{secretRef}))
+		})
+
+		It("should not prune objects that do not belong to the inventory anymore", func() {
+			previous := `[{"kind":"Secret","namespace":"default","name":"my-secret"}]`
+			gomock.InOrder(
+				expectGetParent(previous),
+				expectGetParent(previous),
+				c.EXPECT().Patch(ctx, parent, gomock.Any()),
+				c.EXPECT().Patch(ctx, cm, client.Apply),
+				c.EXPECT().Get(ctx, secretRef.Key, gomock.AssignableToTypeOf(&metav1.PartialObjectMetadata{})),
+				expectGetParent(previous),
+				c.EXPECT().Patch(ctx, parent, gomock.Any()),
+			)
+
+			pruned, err := PatchMultipleWithPrune(ctx, c, inventory, []PatchRequest{{Object: cm, Patch: client.Apply}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pruned).To(BeEmpty())
+		})
+
+		It("should keep tracking the patched objects if pruning fails", func() {
+			previous := `[{"kind":"Secret","namespace":"default","name":"my-secret"}]`
+			pruneErr := fmt.Errorf("some error")
+			gomock.InOrder(
+				expectGetParent(previous),
+				expectGetParent(previous),
+				c.EXPECT().Patch(ctx, parent, gomock.Any()).Do(
+					func(_ context.Context, obj client.Object, _ client.Patch, _ ...client.PatchOption) {
+						Expect(obj.(*corev1.ConfigMap).Data).To(HaveKeyWithValue(InventoryDataKey,
+							`[{"kind":"ConfigMap","namespace":"default","name":"my-cm"},{"kind":"Secret","namespace":"default","name":"my-secret"}]`,
+						))
+					}),
+				c.EXPECT().Patch(ctx, cm, client.Apply),
+				c.EXPECT().Get(ctx, secretRef.Key, gomock.AssignableToTypeOf(&metav1.PartialObjectMetadata{})).Return(pruneErr),
+			)
+
+			_, err := PatchMultipleWithPrune(ctx, c, inventory, []PatchRequest{{Object: cm, Patch: client.Apply}})
+			Expect(errors.Is(err, pruneErr)).To(BeTrue())
+		})
+	})
+})
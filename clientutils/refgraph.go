@@ -0,0 +1,213 @@
+// Copyright 2023 IronCore authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientutils
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ReferenceExtractor extracts the references of an object to other objects.
+type ReferenceExtractor func(obj client.Object) ([]ObjectRef, error)
+
+// ReferenceGraph is an in-memory bidirectional index of references between objects.
+//
+// For each referrer, it maintains the set of objects it references and, for each referenced object,
+// the set of its referrers. Both can be looked up in constant time.
+// A ReferenceGraph is safe for concurrent use.
+type ReferenceGraph struct {
+	scheme  *runtime.Scheme
+	extract ReferenceExtractor
+
+	mu sync.RWMutex
+	// references maps a referrer to the objects it references.
+	references map[ObjectRef]ObjectRefSet
+	// referrers maps a referenced object to the objects referencing it.
+	referrers map[ObjectRef]ObjectRefSet
+}
+
+// NewReferenceGraph creates a new, empty ReferenceGraph. The scheme is used to determine the ObjectRef of objects,
+// the ReferenceExtractor to determine their references.
+func NewReferenceGraph(scheme *runtime.Scheme, extract ReferenceExtractor) *ReferenceGraph {
+	return &ReferenceGraph{
+		scheme:     scheme,
+		extract:    extract,
+		references: make(map[ObjectRef]ObjectRefSet),
+		referrers:  make(map[ObjectRef]ObjectRefSet),
+	}
+}
+
+// Set sets the references of the given referrer, replacing any previously set references.
+func (g *ReferenceGraph) Set(referrer ObjectRef, references ...ObjectRef) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.remove(referrer)
+	if len(references) == 0 {
+		return
+	}
+
+	refs := NewObjectRefSet(references...)
+	g.references[referrer] = refs
+	for ref := range refs {
+		referrers := g.referrers[ref]
+		if referrers == nil {
+			referrers = NewObjectRefSet()
+			g.referrers[ref] = referrers
+		}
+		referrers.Insert(referrer)
+	}
+}
+
+// Remove removes all references of the given referrer.
+func (g *ReferenceGraph) Remove(referrer ObjectRef) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.remove(referrer)
+}
+
+func (g *ReferenceGraph) remove(referrer ObjectRef) {
+	for ref := range g.references[referrer] {
+		referrers := g.referrers[ref]
+		referrers.Delete(referrer)
+		if referrers.Len() == 0 {
+			delete(g.referrers, ref)
+		}
+	}
+	delete(g.references, referrer)
+}
+
+// References returns the objects referenced by the given referrer.
+// The returned ObjectRefSet is a copy and may be modified.
+func (g *ReferenceGraph) References(referrer ObjectRef) ObjectRefSet {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return g.references[referrer].Clone()
+}
+
+// ReferencedBy returns the objects referencing the given object.
+// The returned ObjectRefSet is a copy and may be modified.
+func (g *ReferenceGraph) ReferencedBy(ref ObjectRef) ObjectRefSet {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return g.referrers[ref].Clone()
+}
+
+// IsReferenced reports whether the given object is referenced by any object.
+func (g *ReferenceGraph) IsReferenced(ref ObjectRef) bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return g.referrers[ref].Len() > 0
+}
+
+// Referenced returns all objects that are referenced by any object.
+// This can be used to find dangling references by checking which of them do not exist.
+func (g *ReferenceGraph) Referenced() ObjectRefSet {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	res := make(ObjectRefSet, len(g.referrers))
+	for ref := range g.referrers {
+		res.Insert(ref)
+	}
+	return res
+}
+
+// Update extracts the references of the given object and sets them as its references.
+func (g *ReferenceGraph) Update(obj client.Object) error {
+	referrer, err := ObjectRefFromObject(g.scheme, obj)
+	if err != nil {
+		return fmt.Errorf("error getting object reference: %w", err)
+	}
+
+	refs, err := g.extract(obj)
+	if err != nil {
+		return fmt.Errorf("error extracting references of %s %s: %w", referrer.GroupKind, referrer.Key, err)
+	}
+
+	g.Set(referrer, refs...)
+	return nil
+}
+
+// Delete removes all references of the given object.
+func (g *ReferenceGraph) Delete(obj client.Object) error {
+	referrer, err := ObjectRefFromObject(g.scheme, obj)
+	if err != nil {
+		return fmt.Errorf("error getting object reference: %w", err)
+	}
+
+	g.Remove(referrer)
+	return nil
+}
+
+// ResourceEventHandler returns a toolscache.ResourceEventHandler that keeps the ReferenceGraph
+// up-to-date with the events of an informer of referrers.
+// Errors are reported using utilruntime.HandleError.
+func (g *ReferenceGraph) ResourceEventHandler() toolscache.ResourceEventHandler {
+	update := func(obj interface{}) {
+		o, ok := obj.(client.Object)
+		if !ok {
+			utilruntime.HandleError(fmt.Errorf("unexpected object %T", obj))
+			return
+		}
+		if err := g.Update(o); err != nil {
+			utilruntime.HandleError(err)
+		}
+	}
+	return toolscache.ResourceEventHandlerFuncs{
+		AddFunc: update,
+		UpdateFunc: func(_, newObj interface{}) {
+			update(newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+
+			o, ok := obj.(client.Object)
+			if !ok {
+				utilruntime.HandleError(fmt.Errorf("unexpected object %T", obj))
+				return
+			}
+			if err := g.Delete(o); err != nil {
+				utilruntime.HandleError(err)
+			}
+		},
+	}
+}
+
+// Watch adds the ResourceEventHandler of the ReferenceGraph to the informer of the given referrer type.
+func (g *ReferenceGraph) Watch(ctx context.Context, informers cache.Informers, referrerType client.Object) error {
+	informer, err := informers.GetInformer(ctx, referrerType)
+	if err != nil {
+		return fmt.Errorf("error getting informer for %T: %w", referrerType, err)
+	}
+
+	if _, err := informer.AddEventHandler(g.ResourceEventHandler()); err != nil {
+		return fmt.Errorf("error adding event handler for %T: %w", referrerType, err)
+	}
+	return nil
+}
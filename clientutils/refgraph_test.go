@@ -0,0 +1,99 @@
+// Copyright 2023 IronCore authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientutils_test
+
+import (
+	. "github.com/ironcore-dev/controller-utils/clientutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("ReferenceGraph", func() {
+	var (
+		graph *ReferenceGraph
+	)
+	BeforeEach(func() {
+		graph = NewReferenceGraph(scheme.Scheme, func(obj client.Object) ([]ObjectRef, error) {
+			pod := obj.(*corev1.Pod)
+			var refs []ObjectRef
+			for _, volume := range pod.Spec.Volumes {
+				if volume.Secret != nil {
+					refs = append(refs, ObjectRef{
+						GroupKind: schema.GroupKind{Kind: "Secret"},
+						Key:       client.ObjectKey{Namespace: pod.Namespace, Name: volume.Secret.SecretName},
+					})
+				}
+			}
+			return refs, nil
+		})
+	})
+
+	newRef := func(kind, name string) ObjectRef {
+		return ObjectRef{
+			GroupKind: schema.GroupKind{Kind: kind},
+			Key:       client.ObjectKey{Namespace: corev1.NamespaceDefault, Name: name},
+		}
+	}
+
+	newPod := func(name string, secretNames ...string) *corev1.Pod {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: name}}
+		for _, secretName := range secretNames {
+			pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+				VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: secretName}},
+			})
+		}
+		return pod
+	}
+
+	It("should maintain references in both directions", func() {
+		a, b, x, y := newRef("Pod", "a"), newRef("Pod", "b"), newRef("Secret", "x"), newRef("Secret", "y")
+
+		graph.Set(a, x, y)
+		graph.Set(b, x)
+		Expect(graph.References(a)).To(Equal(NewObjectRefSet(x, y)))
+		Expect(graph.ReferencedBy(x)).To(Equal(NewObjectRefSet(a, b)))
+		Expect(graph.Referenced()).To(Equal(NewObjectRefSet(x, y)))
+
+		graph.Set(a, x)
+		Expect(graph.ReferencedBy(y)).To(BeEmpty())
+		Expect(graph.IsReferenced(y)).To(BeFalse())
+
+		graph.Remove(b)
+		Expect(graph.ReferencedBy(x)).To(Equal(NewObjectRefSet(a)))
+		Expect(graph.References(b)).To(BeEmpty())
+	})
+
+	It("should be updated from informer events", func() {
+		handler := graph.ResourceEventHandler()
+		podRef, x, y := newRef("Pod", "a"), newRef("Secret", "x"), newRef("Secret", "y")
+
+		handler.OnAdd(newPod("a", "x"), false)
+		Expect(graph.References(podRef)).To(Equal(NewObjectRefSet(x)))
+
+		handler.OnUpdate(newPod("a", "x"), newPod("a", "y"))
+		Expect(graph.References(podRef)).To(Equal(NewObjectRefSet(y)))
+		Expect(graph.IsReferenced(x)).To(BeFalse())
+
+		handler.OnDelete(toolscache.DeletedFinalStateUnknown{Obj: newPod("a", "y")})
+		Expect(graph.References(podRef)).To(BeEmpty())
+		Expect(graph.Referenced()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,139 @@
+// Copyright 2023 IronCore authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientutils
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ResolveOptions are options for resolving ObjectRef references.
+type ResolveOptions struct {
+	// Unstructured makes all resolved objects *unstructured.Unstructured, even if their kind
+	// is known to the scheme of the client.
+	Unstructured bool
+}
+
+// ApplyOptions applies the given ResolveOption options to the ResolveOptions.
+func (o *ResolveOptions) ApplyOptions(opts []ResolveOption) *ResolveOptions {
+	for _, opt := range opts {
+		opt.ApplyToResolve(o)
+	}
+	return o
+}
+
+// ApplyToResolve implements ResolveOption.
+func (o *ResolveOptions) ApplyToResolve(o2 *ResolveOptions) {
+	if o.Unstructured {
+		o2.Unstructured = true
+	}
+}
+
+// ResolveOption is an option for resolving ObjectRef references.
+type ResolveOption interface {
+	// ApplyToResolve applies the option to the given ResolveOptions.
+	ApplyToResolve(o *ResolveOptions)
+}
+
+// ResolveUnstructured makes all resolved objects *unstructured.Unstructured.
+var ResolveUnstructured = resolveUnstructured{}
+
+type resolveUnstructured struct{}
+
+// ApplyToResolve implements ResolveOption.
+func (resolveUnstructured) ApplyToResolve(o *ResolveOptions) {
+	o.Unstructured = true
+}
+
+func newObjectForGVK(c client.Client, gvk schema.GroupVersionKind, unstructuredOnly bool) client.Object {
+	if !unstructuredOnly {
+		if obj, err := c.Scheme().New(gvk); err == nil {
+			if obj, ok := obj.(client.Object); ok {
+				obj.GetObjectKind().SetGroupVersionKind(gvk)
+				return obj
+			}
+		}
+	}
+
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(gvk)
+	return u
+}
+
+// ResolveObjectRef gets the object referenced by the given ObjectRef.
+//
+// The version to get the object in is the preferred version of the client's RESTMapper for the group kind
+// of the ObjectRef. If the resulting group version kind is known to the scheme of the client, a typed object is
+// returned, otherwise an *unstructured.Unstructured. Use ResolveUnstructured to always get unstructured objects.
+//
+// If the group kind is unknown to the RESTMapper, an error satisfying meta.IsNoMatchError is returned.
+// If the object does not exist, an error satisfying apierrors.IsNotFound is returned.
+func ResolveObjectRef(ctx context.Context, c client.Client, ref ObjectRef, opts ...ResolveOption) (client.Object, error) {
+	o := (&ResolveOptions{}).ApplyOptions(opts)
+
+	mapping, err := c.RESTMapper().RESTMapping(ref.GroupKind)
+	if err != nil {
+		return nil, fmt.Errorf("error getting mapping for %s: %w", ref.GroupKind, err)
+	}
+
+	obj := newObjectForGVK(c, mapping.GroupVersionKind, o.Unstructured)
+	if err := c.Get(ctx, ref.Key, obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// ResolveResult is the result of resolving an ObjectRefSet.
+type ResolveResult struct {
+	// Objects are the resolved objects by their reference.
+	Objects map[ObjectRef]client.Object
+	// NoKindMatch references the objects whose group kind is unknown to the RESTMapper.
+	NoKindMatch ObjectRefSet
+	// NotFound references the objects that do not exist.
+	NotFound ObjectRefSet
+}
+
+// ResolveObjectRefSet resolves all references of the given ObjectRefSet using ResolveObjectRef.
+//
+// References whose group kind is unknown or whose object does not exist are reported in the ResolveResult.
+// Any other error aborts the resolution and is returned alongside the result up to the error.
+func ResolveObjectRefSet(ctx context.Context, c client.Client, refs ObjectRefSet, opts ...ResolveOption) (ResolveResult, error) {
+	res := ResolveResult{
+		Objects:     make(map[ObjectRef]client.Object, len(refs)),
+		NoKindMatch: NewObjectRefSet(),
+		NotFound:    NewObjectRefSet(),
+	}
+
+	for _, ref := range refs.List() {
+		obj, err := ResolveObjectRef(ctx, c, ref, opts...)
+		switch {
+		case err == nil:
+			res.Objects[ref] = obj
+		case meta.IsNoMatchError(err):
+			res.NoKindMatch.Insert(ref)
+		case apierrors.IsNotFound(err):
+			res.NotFound.Insert(ref)
+		default:
+			return res, fmt.Errorf("error resolving %s %s: %w", ref.GroupKind, ref.Key, err)
+		}
+	}
+	return res, nil
+}
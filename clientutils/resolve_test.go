@@ -0,0 +1,141 @@
+// Copyright 2023 IronCore authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientutils_test
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/ironcore-dev/controller-utils/clientutils"
+	mockclient "github.com/ironcore-dev/controller-utils/mock/controller-runtime/client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Resolve", func() {
+	var (
+		ctx  context.Context
+		ctrl *gomock.Controller
+		c    *mockclient.MockClient
+
+		cmRef      ObjectRef
+		secretRef  ObjectRef
+		unknownRef ObjectRef
+		widgetRef  ObjectRef
+		widgetGVK  schema.GroupVersionKind
+	)
+	BeforeEach(func() {
+		ctx = context.Background()
+		ctrl = gomock.NewController(GinkgoT())
+
+		widgetGVK = schema.GroupVersionKind{Group: "example.org", Version: "v1alpha1", Kind: "Widget"}
+
+		mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{corev1.SchemeGroupVersion, widgetGVK.GroupVersion()})
+		mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+		mapper.Add(corev1.SchemeGroupVersion.WithKind("Secret"), meta.RESTScopeNamespace)
+		mapper.Add(widgetGVK, meta.RESTScopeNamespace)
+
+		c = mockclient.NewMockClient(ctrl)
+		c.EXPECT().Scheme().Return(scheme.Scheme).AnyTimes()
+		c.EXPECT().RESTMapper().Return(mapper).AnyTimes()
+
+		cmRef = ObjectRef{
+			GroupKind: schema.GroupKind{Kind: "ConfigMap"},
+			Key:       client.ObjectKey{Namespace: corev1.NamespaceDefault, Name: "my-cm"},
+		}
+		secretRef = ObjectRef{
+			GroupKind: schema.GroupKind{Kind: "Secret"},
+			Key:       client.ObjectKey{Namespace: corev1.NamespaceDefault, Name: "my-secret"},
+		}
+		unknownRef = ObjectRef{
+			GroupKind: schema.GroupKind{Group: "unknown.example.org", Kind: "Unknown"},
+			Key:       client.ObjectKey{Namespace: corev1.NamespaceDefault, Name: "my-unknown"},
+		}
+		widgetRef = ObjectRef{
+			GroupKind: widgetGVK.GroupKind(),
+			Key:       client.ObjectKey{Namespace: corev1.NamespaceDefault, Name: "my-widget"},
+		}
+	})
+
+	Describe("ResolveObjectRef", func() {
+		It("should get a typed object if the kind is known to the scheme", func() {
+			c.EXPECT().Get(ctx, cmRef.Key, gomock.AssignableToTypeOf(&corev1.ConfigMap{}))
+
+			obj, err := ResolveObjectRef(ctx, c, cmRef)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj).To(BeAssignableToTypeOf(&corev1.ConfigMap{}))
+		})
+
+		It("should get an unstructured object if the kind is not known to the scheme", func() {
+			c.EXPECT().Get(ctx, widgetRef.Key, gomock.AssignableToTypeOf(&unstructured.Unstructured{}))
+
+			obj, err := ResolveObjectRef(ctx, c, widgetRef)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj.GetObjectKind().GroupVersionKind()).To(Equal(widgetGVK))
+		})
+
+		It("should get an unstructured object if requested", func() {
+			c.EXPECT().Get(ctx, cmRef.Key, gomock.AssignableToTypeOf(&unstructured.Unstructured{}))
+
+			obj, err := ResolveObjectRef(ctx, c, cmRef, ResolveUnstructured)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(obj.GetObjectKind().GroupVersionKind()).To(Equal(corev1.SchemeGroupVersion.WithKind("ConfigMap")))
+		})
+
+		It("should return a no match error if the kind is unknown", func() {
+			_, err := ResolveObjectRef(ctx, c, unknownRef)
+			Expect(meta.IsNoMatchError(err)).To(BeTrue(), "unexpected error %v", err)
+		})
+
+		It("should return a not found error if the object does not exist", func() {
+			c.EXPECT().Get(ctx, cmRef.Key, gomock.Any()).
+				Return(apierrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, cmRef.Key.Name))
+
+			_, err := ResolveObjectRef(ctx, c, cmRef)
+			Expect(apierrors.IsNotFound(err)).To(BeTrue(), "unexpected error %v", err)
+		})
+	})
+
+	Describe("ResolveObjectRefSet", func() {
+		It("should resolve all references and report missing kinds and objects distinctly", func() {
+			c.EXPECT().Get(ctx, cmRef.Key, gomock.Any())
+			c.EXPECT().Get(ctx, secretRef.Key, gomock.Any()).
+				Return(apierrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, secretRef.Key.Name))
+
+			res, err := ResolveObjectRefSet(ctx, c, NewObjectRefSet(cmRef, secretRef, unknownRef))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.Objects).To(HaveLen(1))
+			Expect(res.Objects).To(HaveKey(cmRef))
+			Expect(res.NotFound).To(Equal(NewObjectRefSet(secretRef)))
+			Expect(res.NoKindMatch).To(Equal(NewObjectRefSet(unknownRef)))
+		})
+
+		It("should abort on any other error", func() {
+			someErr := fmt.Errorf("some error")
+			c.EXPECT().Get(ctx, cmRef.Key, gomock.Any()).Return(someErr)
+
+			_, err := ResolveObjectRefSet(ctx, c, NewObjectRefSet(cmRef, secretRef))
+			Expect(err).To(MatchError(someErr))
+		})
+	})
+})
@@ -0,0 +1,154 @@
+// Copyright 2023 IronCore authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientutils
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// DefaultRetryBackoff is the backoff used for retrying on conflicts if none is specified.
+var DefaultRetryBackoff = retry.DefaultRetry
+
+// RetryOptions are options for retrying requests on conflicts.
+type RetryOptions struct {
+	// Backoff is the backoff to use between attempts. Its steps limit the number of attempts.
+	// If unset, DefaultRetryBackoff is used.
+	Backoff *wait.Backoff
+}
+
+// ApplyToRetry implements RetryOption.
+func (o *RetryOptions) ApplyToRetry(o2 *RetryOptions) {
+	if o.Backoff != nil {
+		o2.Backoff = o.Backoff
+	}
+}
+
+// ApplyOptions applies all RetryOption to this RetryOptions.
+func (o *RetryOptions) ApplyOptions(opts []RetryOption) *RetryOptions {
+	for _, opt := range opts {
+		opt.ApplyToRetry(o)
+	}
+	return o
+}
+
+func (o *RetryOptions) backoff() wait.Backoff {
+	if o.Backoff != nil {
+		return *o.Backoff
+	}
+	return DefaultRetryBackoff
+}
+
+// RetryOption is an option for retrying requests on conflicts.
+type RetryOption interface {
+	ApplyToRetry(o *RetryOptions)
+}
+
+// WithBackoff specifies the backoff to use when retrying on conflicts.
+type WithBackoff wait.Backoff
+
+// ApplyToRetry implements RetryOption.
+func (w WithBackoff) ApplyToRetry(o *RetryOptions) {
+	backoff := wait.Backoff(w)
+	o.Backoff = &backoff
+}
+
+// PatchWithRetry mutates the given object using mutate and issues a merge patch with optimistic locking
+// if the mutation changed the object semantically.
+//
+// If the patch fails with a conflict, the object is re-fetched, mutated and patched again using the configured
+// backoff. mutate thus has to be idempotent and must only read the object's state from the object itself.
+// If all attempts fail, the last conflict error is returned.
+// The modified result reports whether the object was patched.
+func PatchWithRetry(ctx context.Context, c client.Client, obj client.Object, mutate func() error, opts ...RetryOption) (modified bool, err error) {
+	o := (&RetryOptions{}).ApplyOptions(opts)
+
+	var (
+		attempt int
+		lastErr error
+	)
+	err = wait.ExponentialBackoffWithContext(ctx, o.backoff(), func(ctx context.Context) (bool, error) {
+		if attempt > 0 {
+			if err := c.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+				return false, err
+			}
+		}
+		attempt++
+
+		baseObj := obj.DeepCopyObject().(client.Object)
+		if err := mutate(); err != nil {
+			return false, err
+		}
+		if equality.Semantic.DeepEqual(baseObj, obj) {
+			modified = false
+			return true, nil
+		}
+
+		if err := c.Patch(ctx, obj, client.MergeFromWithOptions(baseObj, client.MergeFromWithOptimisticLock{})); err != nil {
+			if apierrors.IsConflict(err) {
+				lastErr = err
+				return false, nil
+			}
+			return false, err
+		}
+		modified = true
+		return true, nil
+	})
+	if wait.Interrupted(err) && ctx.Err() == nil && lastErr != nil {
+		err = lastErr
+	}
+	if err != nil {
+		return false, err
+	}
+	return modified, nil
+}
+
+// PatchAddFinalizerWithRetry adds the given finalizer to the given object using PatchWithRetry.
+// Unlike PatchAddFinalizer, no patch is issued if the finalizer is already present.
+func PatchAddFinalizerWithRetry(ctx context.Context, c client.Client, obj client.Object, finalizer string, opts ...RetryOption) error {
+	_, err := PatchEnsureFinalizerWithRetry(ctx, c, obj, finalizer, opts...)
+	return err
+}
+
+// PatchRemoveFinalizerWithRetry removes the given finalizer from the given object using PatchWithRetry.
+// Unlike PatchRemoveFinalizer, no patch is issued if the finalizer is already gone.
+func PatchRemoveFinalizerWithRetry(ctx context.Context, c client.Client, obj client.Object, finalizer string, opts ...RetryOption) error {
+	_, err := PatchEnsureNoFinalizerWithRetry(ctx, c, obj, finalizer, opts...)
+	return err
+}
+
+// PatchEnsureFinalizerWithRetry ensures the given object has the given finalizer using PatchWithRetry.
+// The modified result reports whether the object had to be modified.
+func PatchEnsureFinalizerWithRetry(ctx context.Context, c client.Client, obj client.Object, finalizer string, opts ...RetryOption) (modified bool, err error) {
+	return PatchWithRetry(ctx, c, obj, func() error {
+		controllerutil.AddFinalizer(obj, finalizer)
+		return nil
+	}, opts...)
+}
+
+// PatchEnsureNoFinalizerWithRetry ensures the given object does not have the given finalizer using PatchWithRetry.
+// The modified result reports whether the object had to be modified.
+func PatchEnsureNoFinalizerWithRetry(ctx context.Context, c client.Client, obj client.Object, finalizer string, opts ...RetryOption) (modified bool, err error) {
+	return PatchWithRetry(ctx, c, obj, func() error {
+		controllerutil.RemoveFinalizer(obj, finalizer)
+		return nil
+	}, opts...)
+}
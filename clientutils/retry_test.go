@@ -0,0 +1,168 @@
+// Copyright 2023 IronCore authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientutils_test
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/ironcore-dev/controller-utils/clientutils"
+	mockclient "github.com/ironcore-dev/controller-utils/mock/controller-runtime/client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Retry", func() {
+	const finalizer = "my-finalizer"
+
+	var (
+		ctx  context.Context
+		ctrl *gomock.Controller
+
+		c *mockclient.MockClient
+
+		cm          *corev1.ConfigMap
+		conflictErr error
+		backoff     WithBackoff
+	)
+	BeforeEach(func() {
+		ctx = context.Background()
+		ctrl = gomock.NewController(GinkgoT())
+
+		c = mockclient.NewMockClient(ctrl)
+
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:       corev1.NamespaceDefault,
+				Name:            "my-cm",
+				ResourceVersion: "1",
+			},
+		}
+		conflictErr = apierrors.NewConflict(corev1.Resource("configmaps"), cm.Name, fmt.Errorf("conflict"))
+		backoff = WithBackoff(wait.Backoff{Steps: 3})
+	})
+
+	expectPatchWithResourceVersion := func(resourceVersion string) *gomock.Call {
+		return c.EXPECT().Patch(ctx, cm, gomock.Any()).DoAndReturn(
+			func(_ context.Context, obj client.Object, patch client.Patch, _ ...client.PatchOption) error {
+				data, err := patch.Data(obj)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(data)).To(ContainSubstring(fmt.Sprintf(`"resourceVersion":"%s"`, resourceVersion)))
+				return nil
+			})
+	}
+
+	expectGetWithResourceVersion := func(resourceVersion string, finalizers ...string) *gomock.Call {
+		return c.EXPECT().Get(ctx, client.ObjectKeyFromObject(cm), cm).DoAndReturn(
+			func(_ context.Context, _ client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+				*obj.(*corev1.ConfigMap) = corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace:       cm.Namespace,
+						Name:            cm.Name,
+						ResourceVersion: resourceVersion,
+						Finalizers:      finalizers,
+					},
+				}
+				return nil
+			})
+	}
+
+	Describe("PatchWithRetry", func() {
+		It("should patch the object with optimistic locking", func() {
+			expectPatchWithResourceVersion("1")
+
+			modified, err := PatchWithRetry(ctx, c, cm, func() error {
+				cm.Data = map[string]string{"foo": "bar"}
+				return nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(modified).To(BeTrue())
+		})
+
+		It("should not patch the object if the mutation does not change it", func() {
+			modified, err := PatchWithRetry(ctx, c, cm, func() error { return nil })
+			Expect(err).NotTo(HaveOccurred())
+			Expect(modified).To(BeFalse())
+		})
+
+		It("should re-fetch and retry on conflict", func() {
+			gomock.InOrder(
+				c.EXPECT().Patch(ctx, cm, gomock.Any()).Return(conflictErr),
+				expectGetWithResourceVersion("2"),
+				expectPatchWithResourceVersion("2"),
+			)
+
+			modified, err := PatchWithRetry(ctx, c, cm, func() error {
+				cm.Data = map[string]string{"foo": "bar"}
+				return nil
+			}, backoff)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(modified).To(BeTrue())
+		})
+
+		It("should return the last conflict if all attempts fail", func() {
+			c.EXPECT().Patch(ctx, cm, gomock.Any()).Return(conflictErr).Times(3)
+			expectGetWithResourceVersion("2").Times(2)
+
+			_, err := PatchWithRetry(ctx, c, cm, func() error {
+				cm.Data = map[string]string{"foo": "bar"}
+				return nil
+			}, backoff)
+			Expect(apierrors.IsConflict(err)).To(BeTrue())
+		})
+
+		It("should not retry on other errors", func() {
+			c.EXPECT().Patch(ctx, cm, gomock.Any()).Return(fmt.Errorf("some error"))
+
+			_, err := PatchWithRetry(ctx, c, cm, func() error {
+				cm.Data = map[string]string{"foo": "bar"}
+				return nil
+			}, backoff)
+			Expect(err).To(MatchError("some error"))
+		})
+	})
+
+	Describe("PatchEnsureFinalizerWithRetry", func() {
+		It("should not patch if the re-fetched object already has the finalizer", func() {
+			gomock.InOrder(
+				c.EXPECT().Patch(ctx, cm, gomock.Any()).Return(conflictErr),
+				expectGetWithResourceVersion("2", finalizer),
+			)
+
+			modified, err := PatchEnsureFinalizerWithRetry(ctx, c, cm, finalizer, backoff)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(modified).To(BeFalse())
+			Expect(cm.Finalizers).To(ConsistOf(finalizer))
+		})
+	})
+
+	Describe("PatchEnsureNoFinalizerWithRetry", func() {
+		It("should remove the finalizer", func() {
+			cm.Finalizers = []string{finalizer}
+			expectPatchWithResourceVersion("1")
+
+			modified, err := PatchEnsureNoFinalizerWithRetry(ctx, c, cm, finalizer)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(modified).To(BeTrue())
+			Expect(cm.Finalizers).To(BeEmpty())
+		})
+	})
+})
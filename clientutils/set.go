@@ -0,0 +1,109 @@
+// Copyright 2023 IronCore authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientutils
+
+import (
+	"sort"
+)
+
+// The set algebra of ObjectKeySet, ObjectRefSet and GetRequestSet is implemented once by the
+// functions below. A set is represented as a map from the identity of an item to an associated value.
+// For items present in both operands of an operation, the value of the first operand is retained.
+
+func setClone[M ~map[K]V, K comparable, V any](s M) M {
+	res := make(M, len(s))
+	for k, v := range s {
+		res[k] = v
+	}
+	return res
+}
+
+func setUnion[M ~map[K]V, K comparable, V any](s, s2 M) M {
+	res := setClone(s)
+	for k, v := range s2 {
+		if _, ok := res[k]; !ok {
+			res[k] = v
+		}
+	}
+	return res
+}
+
+func setIntersection[M ~map[K]V, K comparable, V any](s, s2 M) M {
+	res := make(M)
+	for k, v := range s {
+		if _, ok := s2[k]; ok {
+			res[k] = v
+		}
+	}
+	return res
+}
+
+func setDifference[M ~map[K]V, K comparable, V any](s, s2 M) M {
+	res := make(M)
+	for k, v := range s {
+		if _, ok := s2[k]; !ok {
+			res[k] = v
+		}
+	}
+	return res
+}
+
+func setSymmetricDifference[M ~map[K]V, K comparable, V any](s, s2 M) M {
+	return setUnion(setDifference(s, s2), setDifference(s2, s))
+}
+
+func setIsSuperset[M ~map[K]V, K comparable, V any](s, s2 M) bool {
+	for k := range s2 {
+		if _, ok := s[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func setEqual[M ~map[K]V, K comparable, V any](s, s2 M) bool {
+	return len(s) == len(s2) && setIsSuperset(s, s2)
+}
+
+// setUnsortedList returns the items of the set in arbitrary order, using item to construct them.
+func setUnsortedList[M ~map[K]V, K comparable, V any, T any](s M, item func(K, V) T) []T {
+	res := make([]T, 0, len(s))
+	for k, v := range s {
+		res = append(res, item(k, v))
+	}
+	return res
+}
+
+// setList returns the items of the set sorted by their identity using less, using item to construct them.
+func setList[M ~map[K]V, K comparable, V any, T any](s M, less func(a, b K) bool, item func(K, V) T) []T {
+	keys := make([]K, 0, len(s))
+	for k := range s {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return less(keys[i], keys[j])
+	})
+
+	res := make([]T, len(keys))
+	for i, k := range keys {
+		res[i] = item(k, s[k])
+	}
+	return res
+}
+
+// setKey is an item constructor for setUnsortedList and setList for sets whose items are their identity.
+func setKey[K comparable](k K, _ struct{}) K {
+	return k
+}
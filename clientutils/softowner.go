@@ -0,0 +1,146 @@
+// Copyright 2023 IronCore authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientutils
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ironcore-dev/controller-utils/metautils"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// SoftOwnerAnnotation is the annotation the soft owner of an object is recorded in.
+//
+// Unlike owner references, soft owners may reside in another namespace than the owned object
+// and cluster-scoped objects may be soft owned by namespaced objects.
+// Soft owners are not considered by the garbage collector.
+// The soft owner is recorded in the text encoding of ObjectRef, see ObjectRef.String.
+const SoftOwnerAnnotation = "controller-utils.ironcore.dev/owner"
+
+// SetSoftOwnerRef records the given ObjectRef as soft owner of the given object.
+func SetSoftOwnerRef(obj client.Object, ref ObjectRef) error {
+	if ref == (ObjectRef{}) {
+		return fmt.Errorf("must specify soft owner")
+	}
+
+	data, err := ref.MarshalText()
+	if err != nil {
+		return fmt.Errorf("error encoding soft owner: %w", err)
+	}
+
+	metautils.SetAnnotation(obj, SoftOwnerAnnotation, string(data))
+	return nil
+}
+
+// SetSoftOwner records the given owner as soft owner of the given object.
+func SetSoftOwner(scheme *runtime.Scheme, obj, owner client.Object) error {
+	ref, err := ObjectRefFromObject(scheme, owner)
+	if err != nil {
+		return fmt.Errorf("error getting owner reference: %w", err)
+	}
+	return SetSoftOwnerRef(obj, ref)
+}
+
+// RemoveSoftOwner removes the soft owner record from the given object.
+func RemoveSoftOwner(obj client.Object) {
+	metautils.DeleteAnnotation(obj, SoftOwnerAnnotation)
+}
+
+// GetSoftOwner returns the soft owner of the given object and whether it has one.
+func GetSoftOwner(obj client.Object) (ObjectRef, bool, error) {
+	data, ok := obj.GetAnnotations()[SoftOwnerAnnotation]
+	if !ok {
+		return ObjectRef{}, false, nil
+	}
+
+	ref, err := ParseObjectRef(data)
+	if err != nil {
+		return ObjectRef{}, false, fmt.Errorf("error decoding soft owner: %w", err)
+	}
+	return ref, true, nil
+}
+
+// IsSoftOwnedBy checks if the given object is soft owned by the given owner.
+func IsSoftOwnedBy(scheme *runtime.Scheme, owner, obj client.Object) (bool, error) {
+	ref, ok, err := GetSoftOwner(obj)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	ownerRef, err := ObjectRefFromObject(scheme, owner)
+	if err != nil {
+		return false, fmt.Errorf("error getting owner reference: %w", err)
+	}
+	return ref == ownerRef, nil
+}
+
+// FilterSoftOwnedBy filters multiple objects by using IsSoftOwnedBy on each item.
+func FilterSoftOwnedBy(scheme *runtime.Scheme, owner client.Object, objects []client.Object) ([]client.Object, error) {
+	var filtered []client.Object
+	for _, object := range objects {
+		ok, err := IsSoftOwnedBy(scheme, owner, object)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			object := object
+			filtered = append(filtered, object)
+		}
+	}
+	return filtered, nil
+}
+
+// ListAndFilterSoftOwnedBy is a shorthand for doing a client.List followed by filtering the list's elements
+// using IsSoftOwnedBy.
+func ListAndFilterSoftOwnedBy(ctx context.Context, c client.Client, owner client.Object, list client.ObjectList, opts ...client.ListOption) error {
+	scheme := c.Scheme()
+	return ListAndFilter(ctx, c, list, func(object client.Object) (bool, error) {
+		return IsSoftOwnedBy(scheme, owner, object)
+	}, opts...)
+}
+
+// SoftOwnerMapFunc returns a handler.MapFunc that maps an object to a reconcile.Request for its soft owner,
+// if the soft owner is of the type of the given ownerType.
+// Objects without or with an invalid soft owner record are not mapped.
+func SoftOwnerMapFunc(scheme *runtime.Scheme, ownerType client.Object) (handler.MapFunc, error) {
+	gvk, err := apiutil.GVKForObject(ownerType, scheme)
+	if err != nil {
+		return nil, fmt.Errorf("error getting kind of owner type: %w", err)
+	}
+
+	ownerGroupKind := gvk.GroupKind()
+	return func(_ context.Context, obj client.Object) []reconcile.Request {
+		ref, ok, err := GetSoftOwner(obj)
+		if err != nil || !ok || ref.GroupKind != ownerGroupKind {
+			return nil
+		}
+		return []reconcile.Request{{NamespacedName: ref.Key}}
+	}, nil
+}
+
+// EnqueueRequestForSoftOwner returns a handler.EventHandler that enqueues a reconcile.Request for the soft owner
+// of the object an event occurs for, if the soft owner is of the type of the given ownerType.
+func EnqueueRequestForSoftOwner(scheme *runtime.Scheme, ownerType client.Object) (handler.EventHandler, error) {
+	mapFunc, err := SoftOwnerMapFunc(scheme, ownerType)
+	if err != nil {
+		return nil, err
+	}
+	return handler.EnqueueRequestsFromMapFunc(mapFunc), nil
+}
@@ -0,0 +1,125 @@
+// Copyright 2023 IronCore authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientutils_test
+
+import (
+	"context"
+
+	. "github.com/ironcore-dev/controller-utils/clientutils"
+	mockclient "github.com/ironcore-dev/controller-utils/mock/controller-runtime/client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("SoftOwner", func() {
+	var (
+		ctx  context.Context
+		ctrl *gomock.Controller
+
+		c *mockclient.MockClient
+
+		owner    *corev1.ConfigMap
+		ownerRef ObjectRef
+		ns       *corev1.Namespace
+	)
+	BeforeEach(func() {
+		ctx = context.Background()
+		ctrl = gomock.NewController(GinkgoT())
+
+		c = mockclient.NewMockClient(ctrl)
+		c.EXPECT().Scheme().Return(scheme.Scheme).AnyTimes()
+
+		owner = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "owner-ns",
+				Name:      "owner",
+			},
+		}
+		ownerRef = ObjectRef{
+			GroupKind: schema.GroupKind{Kind: "ConfigMap"},
+			Key:       client.ObjectKey{Namespace: "owner-ns", Name: "owner"},
+		}
+		ns = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "my-ns"}}
+	})
+
+	Describe("SetSoftOwner", func() {
+		It("should record the soft owner so that it can be read again", func() {
+			Expect(SetSoftOwner(scheme.Scheme, ns, owner)).To(Succeed())
+			Expect(ns.Annotations).To(HaveKeyWithValue(SoftOwnerAnnotation, "/ConfigMap/owner-ns/owner"))
+
+			ref, ok, err := GetSoftOwner(ns)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(ref).To(Equal(ownerRef))
+
+			RemoveSoftOwner(ns)
+			_, ok, err = GetSoftOwner(ns)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Describe("SetSoftOwnerRef", func() {
+		It("should error on the zero reference", func() {
+			Expect(SetSoftOwnerRef(ns, ObjectRef{})).To(MatchError("must specify soft owner"))
+			Expect(ns.Annotations).NotTo(HaveKey(SoftOwnerAnnotation))
+		})
+	})
+
+	Describe("GetSoftOwner", func() {
+		It("should error on an invalid soft owner record", func() {
+			ns.Annotations = map[string]string{SoftOwnerAnnotation: "invalid"}
+			_, _, err := GetSoftOwner(ns)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("ListAndFilterSoftOwnedBy", func() {
+		It("should only keep the objects soft owned by the owner", func() {
+			otherNS := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "other-ns"}}
+			Expect(SetSoftOwner(scheme.Scheme, ns, owner)).To(Succeed())
+
+			c.EXPECT().List(ctx, gomock.AssignableToTypeOf(&corev1.NamespaceList{})).Do(
+				func(_ context.Context, list client.ObjectList, _ ...client.ListOption) {
+					list.(*corev1.NamespaceList).Items = []corev1.Namespace{*ns, *otherNS}
+				})
+
+			list := &corev1.NamespaceList{}
+			Expect(ListAndFilterSoftOwnedBy(ctx, c, owner, list)).To(Succeed())
+			Expect(list.Items).To(Equal([]corev1.Namespace{*ns}))
+		})
+	})
+
+	Describe("SoftOwnerMapFunc", func() {
+		It("should map objects to their soft owner of the owner type", func() {
+			Expect(SetSoftOwner(scheme.Scheme, ns, owner)).To(Succeed())
+
+			mapFunc, err := SoftOwnerMapFunc(scheme.Scheme, &corev1.ConfigMap{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mapFunc(ctx, ns)).To(Equal([]reconcile.Request{{NamespacedName: ownerRef.Key}}))
+
+			otherMapFunc, err := SoftOwnerMapFunc(scheme.Scheme, &corev1.Secret{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(otherMapFunc(ctx, ns)).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,136 @@
+// Copyright 2023 IronCore authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientutils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// FieldIndex is a field index declared for objects of type T.
+//
+// A FieldIndex should be declared once, e.g. as package-level variable, and used for registering,
+// initializing and querying the index. That way, the field name and the object type cannot diverge.
+//
+// Registering the same FieldIndex (or a copy of it) multiple times on a SharedFieldIndexer succeeds,
+// allowing multiple packages to register the indices they depend on. Registering a different FieldIndex
+// declaration for the same object type and field errors, even if its extract function is the same.
+type FieldIndex[T client.Object] struct {
+	*fieldIndex[T]
+}
+
+type fieldIndex[T client.Object] struct {
+	obj     T
+	field   string
+	extract func(obj T) []string
+}
+
+// NewFieldIndex declares a new FieldIndex with the given field name for objects of the type of the given object.
+// extract returns the index values of an object.
+//
+// The given object is only used to determine the type to index. For *unstructured.Unstructured and
+// *metav1.PartialObjectMetadata, its group version kind has to be set.
+func NewFieldIndex[T client.Object](obj T, field string, extract func(obj T) []string) FieldIndex[T] {
+	return FieldIndex[T]{
+		fieldIndex: &fieldIndex[T]{
+			obj:     obj,
+			field:   field,
+			extract: extract,
+		},
+	}
+}
+
+// Field returns the field name of the index.
+func (i FieldIndex[T]) Field() string {
+	return i.field
+}
+
+// Object returns a new object of the indexed type.
+func (i FieldIndex[T]) Object() T {
+	return i.obj.DeepCopyObject().(T)
+}
+
+// IndexerFunc returns a client.IndexerFunc that calls extract for objects of type T
+// and returns no values for all other objects.
+func (i FieldIndex[T]) IndexerFunc() client.IndexerFunc {
+	return func(obj client.Object) []string {
+		t, ok := obj.(T)
+		if !ok {
+			return nil
+		}
+		return i.extract(t)
+	}
+}
+
+// Register registers the index on the given SharedFieldIndexer.
+// If the same FieldIndex is already registered, Register is a no-op.
+func (i FieldIndex[T]) Register(s *SharedFieldIndexer) error {
+	return s.register(i.Object(), i.field, i.IndexerFunc(), i.fieldIndex)
+}
+
+// MustRegister registers the index on the given SharedFieldIndexer and panics on error.
+func (i FieldIndex[T]) MustRegister(s *SharedFieldIndexer) {
+	utilruntime.Must(i.Register(s))
+}
+
+// IndexField initializes the index on the given SharedFieldIndexer. The index has to be registered before.
+func (i FieldIndex[T]) IndexField(ctx context.Context, s *SharedFieldIndexer) error {
+	return s.IndexField(ctx, i.Object(), i.field)
+}
+
+// MatchingField returns a client.MatchingFields list option that matches objects with the given index value.
+func (i FieldIndex[T]) MatchingField(value string) client.MatchingFields {
+	return client.MatchingFields{i.field: value}
+}
+
+// CheckInitialized returns an error if any registered index has not been initialized via IndexField.
+func (s *SharedFieldIndexer) CheckInitialized() error {
+	var uninitialized []string
+	for _, info := range s.Indices() {
+		if !info.Initialized {
+			uninitialized = append(uninitialized, info.String())
+		}
+	}
+	if len(uninitialized) > 0 {
+		return fmt.Errorf("field indices not initialized: [%s]", strings.Join(uninitialized, ", "))
+	}
+	return nil
+}
+
+type initializedCheck struct {
+	indexer *SharedFieldIndexer
+}
+
+// Start implements manager.Runnable.
+func (c initializedCheck) Start(context.Context) error {
+	return c.indexer.CheckInitialized()
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable.
+func (c initializedCheck) NeedLeaderElection() bool {
+	return false
+}
+
+// InitializedCheck returns a manager.Runnable that fails the manager on start if any index registered on
+// the given SharedFieldIndexer has not been initialized via IndexField.
+// Add it to the manager after all controllers have been set up.
+func InitializedCheck(s *SharedFieldIndexer) manager.Runnable {
+	return initializedCheck{indexer: s}
+}
@@ -0,0 +1,115 @@
+// Copyright 2023 IronCore authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientutils_test
+
+import (
+	"context"
+
+	. "github.com/ironcore-dev/controller-utils/clientutils"
+	mockclient "github.com/ironcore-dev/controller-utils/mock/controller-runtime/client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("TypedIndex", func() {
+	var (
+		ctx  context.Context
+		ctrl *gomock.Controller
+
+		fieldIndexer *mockclient.MockFieldIndexer
+		indexer      *SharedFieldIndexer
+
+		nodeNameIndex FieldIndex[*corev1.Pod]
+	)
+	BeforeEach(func() {
+		ctx = context.Background()
+		ctrl = gomock.NewController(GinkgoT())
+
+		fieldIndexer = mockclient.NewMockFieldIndexer(ctrl)
+		indexer = NewSharedFieldIndexer(fieldIndexer, scheme.Scheme)
+
+		nodeNameIndex = NewFieldIndex(&corev1.Pod{}, "spec.nodeName", func(pod *corev1.Pod) []string {
+			return []string{pod.Spec.NodeName}
+		})
+	})
+
+	Describe("FieldIndex", func() {
+		It("should register and initialize the index", func() {
+			fieldIndexer.EXPECT().IndexField(ctx, &corev1.Pod{}, "spec.nodeName", gomock.Any()).Do(
+				func(_ context.Context, _ client.Object, _ string, f client.IndexerFunc) {
+					Expect(f(&corev1.Pod{Spec: corev1.PodSpec{NodeName: "my-node"}})).To(Equal([]string{"my-node"}))
+					Expect(f(&corev1.Secret{})).To(BeEmpty())
+				})
+
+			Expect(nodeNameIndex.Register(indexer)).To(Succeed())
+			Expect(nodeNameIndex.IndexField(ctx, indexer)).To(Succeed())
+		})
+
+		It("should allow registering the same index multiple times", func() {
+			Expect(nodeNameIndex.Register(indexer)).To(Succeed())
+			Expect(nodeNameIndex.Register(indexer)).To(Succeed())
+		})
+
+		It("should allow registering a copy of the index", func() {
+			indexCopy := nodeNameIndex
+
+			Expect(nodeNameIndex.Register(indexer)).To(Succeed())
+			Expect(indexCopy.Register(indexer)).To(Succeed())
+		})
+
+		It("should error registering a different index with an identical extract function for the same field", func() {
+			extract := func(pod *corev1.Pod) []string { return []string{pod.Spec.NodeName} }
+			index := NewFieldIndex(&corev1.Pod{}, "spec.nodeName", extract)
+			otherIndex := NewFieldIndex(&corev1.Pod{}, "spec.nodeName", extract)
+
+			Expect(index.Register(indexer)).To(Succeed())
+			Expect(otherIndex.Register(indexer)).To(MatchError("indexer for type *v1.Pod field spec.nodeName already registered"))
+		})
+
+		It("should error registering an index with a different extract function for the same field", func() {
+			otherIndex := NewFieldIndex(&corev1.Pod{}, "spec.nodeName", func(pod *corev1.Pod) []string {
+				return []string{pod.Spec.NodeName}
+			})
+
+			Expect(nodeNameIndex.Register(indexer)).To(Succeed())
+			Expect(otherIndex.Register(indexer)).To(MatchError("indexer for type *v1.Pod field spec.nodeName already registered"))
+		})
+
+		It("should create a list option matching the field", func() {
+			Expect(nodeNameIndex.MatchingField("my-node")).To(Equal(client.MatchingFields{"spec.nodeName": "my-node"}))
+		})
+	})
+
+	Describe("CheckInitialized", func() {
+		It("should error if a registered index was not initialized", func() {
+			Expect(nodeNameIndex.Register(indexer)).To(Succeed())
+
+			Expect(indexer.CheckInitialized()).To(MatchError("field indices not initialized: [/v1, Kind=Pod (structured) field spec.nodeName]"))
+			Expect(InitializedCheck(indexer).Start(ctx)).To(HaveOccurred())
+		})
+
+		It("should succeed if all registered indices were initialized", func() {
+			fieldIndexer.EXPECT().IndexField(ctx, &corev1.Pod{}, "spec.nodeName", gomock.Any())
+			Expect(nodeNameIndex.Register(indexer)).To(Succeed())
+			Expect(nodeNameIndex.IndexField(ctx, indexer)).To(Succeed())
+
+			Expect(indexer.CheckInitialized()).To(Succeed())
+		})
+	})
+})
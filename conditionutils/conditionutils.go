@@ -14,6 +14,8 @@
 
 // Package conditionutils simplifies condition handling with any structurally compatible condition
 // (comparable to a sort of duck-typing) via go reflection.
+// For hot paths, TypedAccessor offers the same operations using typed getter and setter functions.
+// UnstructuredAccessor offers them for conditions of unstructured objects.
 package conditionutils
 
 import (
@@ -1023,6 +1025,12 @@ func (u UpdateStatus) ApplyUpdate(a *Accessor, condPtr interface{}) error {
 	return a.SetStatus(condPtr, corev1.ConditionStatus(u))
 }
 
+// ApplyToCondition implements TypedUpdateOption.
+func (u UpdateStatus) ApplyToCondition(w ConditionWriter) error {
+	w.SetStatus(corev1.ConditionStatus(u))
+	return nil
+}
+
 // UpdateMessage implements UpdateOption to set the message.
 type UpdateMessage string
 
@@ -1031,6 +1039,12 @@ func (u UpdateMessage) ApplyUpdate(a *Accessor, condPtr interface{}) error {
 	return a.SetMessage(condPtr, string(u))
 }
 
+// ApplyToCondition implements TypedUpdateOption.
+func (u UpdateMessage) ApplyToCondition(w ConditionWriter) error {
+	w.SetMessage(string(u))
+	return nil
+}
+
 // UpdateReason implements UpdateOption to set the reason.
 type UpdateReason string
 
@@ -1039,6 +1053,12 @@ func (u UpdateReason) ApplyUpdate(a *Accessor, condPtr interface{}) error {
 	return a.SetReason(condPtr, string(u))
 }
 
+// ApplyToCondition implements TypedUpdateOption.
+func (u UpdateReason) ApplyToCondition(w ConditionWriter) error {
+	w.SetReason(string(u))
+	return nil
+}
+
 // UpdateObservedGeneration implements UpdateOption to set the observed generation.
 type UpdateObservedGeneration int64
 
@@ -1047,6 +1067,12 @@ func (u UpdateObservedGeneration) ApplyUpdate(a *Accessor, condPtr interface{})
 	return a.SetObservedGeneration(condPtr, int64(u))
 }
 
+// ApplyToCondition implements TypedUpdateOption.
+func (u UpdateObservedGeneration) ApplyToCondition(w ConditionWriter) error {
+	w.SetObservedGeneration(int64(u))
+	return nil
+}
+
 // UpdateObserved is a shorthand for updating the observed generation from a metav1.Object's generation.
 func UpdateObserved(obj metav1.Object) UpdateObservedGeneration {
 	return UpdateObservedGeneration(obj.GetGeneration())
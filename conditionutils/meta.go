@@ -0,0 +1,98 @@
+// Copyright 2023 IronCore authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conditionutils
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ValidateMetaCondition validates the given metav1.Condition using the upstream validation rules:
+// The type has to be a qualified name, the status has to be one of True, False or Unknown, the reason
+// has to be set, match the reason format and be at most 1024 characters long, the message may be at most
+// 32768 characters long and the last transition time has to be set.
+func ValidateMetaCondition(cond metav1.Condition) error {
+	return metav1validation.ValidateCondition(cond, nil).ToAggregate()
+}
+
+// ValidateMetaConditions validates the given metav1.Condition slice like ValidateMetaCondition and
+// additionally ensures that no condition type occurs more than once.
+func ValidateMetaConditions(conds []metav1.Condition) error {
+	return metav1validation.ValidateConditions(conds, field.NewPath("conditions")).ToAggregate()
+}
+
+// MetaConditionAccessor is a TypedAccessor for metav1.Condition.
+//
+// Updated conditions are validated using ValidateMetaCondition.
+var MetaConditionAccessor = NewTypedAccessor(TypedAccessorOptions[metav1.Condition]{
+	GetType:   func(cond metav1.Condition) string { return cond.Type },
+	SetType:   func(cond *metav1.Condition, typ string) { cond.Type = typ },
+	GetStatus: func(cond metav1.Condition) corev1.ConditionStatus { return corev1.ConditionStatus(cond.Status) },
+	SetStatus: func(cond *metav1.Condition, status corev1.ConditionStatus) {
+		cond.Status = metav1.ConditionStatus(status)
+	},
+	GetReason:             func(cond metav1.Condition) string { return cond.Reason },
+	SetReason:             func(cond *metav1.Condition, reason string) { cond.Reason = reason },
+	GetMessage:            func(cond metav1.Condition) string { return cond.Message },
+	SetMessage:            func(cond *metav1.Condition, message string) { cond.Message = message },
+	GetLastTransitionTime: func(cond metav1.Condition) metav1.Time { return cond.LastTransitionTime },
+	SetLastTransitionTime: func(cond *metav1.Condition, t metav1.Time) { cond.LastTransitionTime = t },
+	GetObservedGeneration: func(cond metav1.Condition) int64 { return cond.ObservedGeneration },
+	SetObservedGeneration: func(cond *metav1.Condition, gen int64) { cond.ObservedGeneration = gen },
+	Validate:              ValidateMetaCondition,
+})
+
+// UpdateMetaStatus implements TypedUpdateOption to set a metav1.ConditionStatus.
+type UpdateMetaStatus metav1.ConditionStatus
+
+// ApplyToCondition implements TypedUpdateOption.
+func (u UpdateMetaStatus) ApplyToCondition(w ConditionWriter) error {
+	w.SetStatus(corev1.ConditionStatus(u))
+	return nil
+}
+
+var (
+	// UpdateMeta updates the metav1.Condition with the given options.
+	// See TypedAccessor.Update for more.
+	UpdateMeta = MetaConditionAccessor.Update
+
+	// MustUpdateMeta updates the metav1.Condition with the given options.
+	// See TypedAccessor.MustUpdate for more.
+	MustUpdateMeta = MetaConditionAccessor.MustUpdate
+
+	// UpdateMetaSlice updates the metav1.Condition slice with the given options.
+	// See TypedAccessor.UpdateSlice for more.
+	UpdateMetaSlice = MetaConditionAccessor.UpdateSlice
+
+	// MustUpdateMetaSlice updates the metav1.Condition slice with the given options.
+	// See TypedAccessor.MustUpdateSlice for more.
+	MustUpdateMetaSlice = MetaConditionAccessor.MustUpdateSlice
+
+	// FindMetaSliceIndex finds the index of the target condition in the given metav1.Condition slice.
+	// See TypedAccessor.FindSliceIndex for more.
+	FindMetaSliceIndex = MetaConditionAccessor.FindSliceIndex
+
+	// FindMetaSlice finds the target condition in the given metav1.Condition slice.
+	// See TypedAccessor.FindSlice for more.
+	FindMetaSlice = MetaConditionAccessor.FindSlice
+)
+
+// FindMetaSliceStatus finds the status of the condition with the given type in the given metav1.Condition slice.
+// If the condition cannot be found, metav1.ConditionUnknown is returned.
+func FindMetaSliceStatus(conds []metav1.Condition, typ string) metav1.ConditionStatus {
+	return metav1.ConditionStatus(MetaConditionAccessor.FindSliceStatus(conds, typ))
+}
@@ -0,0 +1,108 @@
+// Copyright 2023 IronCore authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conditionutils_test
+
+import (
+	"strings"
+
+	. "github.com/ironcore-dev/controller-utils/conditionutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Meta", func() {
+	var (
+		cond metav1.Condition
+	)
+	BeforeEach(func() {
+		cond = metav1.Condition{
+			Type:               "Ready",
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: 1,
+			LastTransitionTime: metav1.Unix(1, 0),
+			Reason:             "Ready",
+			Message:            "The object is ready.",
+		}
+	})
+
+	Describe("ValidateMetaCondition", func() {
+		It("should accept a valid condition", func() {
+			Expect(ValidateMetaCondition(cond)).To(Succeed())
+		})
+
+		DescribeTable("should reject invalid conditions",
+			func(mutate func(cond *metav1.Condition)) {
+				mutate(&cond)
+				Expect(ValidateMetaCondition(cond)).NotTo(Succeed())
+			},
+			Entry("invalid type", func(cond *metav1.Condition) { cond.Type = "not a type" }),
+			Entry("invalid status", func(cond *metav1.Condition) { cond.Status = "Maybe" }),
+			Entry("missing reason", func(cond *metav1.Condition) { cond.Reason = "" }),
+			Entry("invalid reason format", func(cond *metav1.Condition) { cond.Reason = "not-a-reason" }),
+			Entry("reason too long", func(cond *metav1.Condition) { cond.Reason = strings.Repeat("a", 1025) }),
+			Entry("message too long", func(cond *metav1.Condition) { cond.Message = strings.Repeat("a", 32*1024+1) }),
+			Entry("missing last transition time", func(cond *metav1.Condition) { cond.LastTransitionTime = metav1.Time{} }),
+		)
+	})
+
+	Describe("ValidateMetaConditions", func() {
+		It("should reject duplicate condition types", func() {
+			Expect(ValidateMetaConditions([]metav1.Condition{cond})).To(Succeed())
+			Expect(ValidateMetaConditions([]metav1.Condition{cond, cond})).NotTo(Succeed())
+		})
+	})
+
+	Describe("UpdateMetaSlice", func() {
+		It("should update metav1.Condition slices", func() {
+			var conds []metav1.Condition
+			Expect(UpdateMetaSlice(&conds, "Ready",
+				UpdateMetaStatus(metav1.ConditionTrue),
+				UpdateReason("Ready"),
+				UpdateObservedGeneration(3),
+			)).To(Succeed())
+
+			Expect(conds).To(HaveLen(1))
+			Expect(conds[0].Type).To(Equal("Ready"))
+			Expect(conds[0].Status).To(Equal(metav1.ConditionTrue))
+			Expect(conds[0].Reason).To(Equal("Ready"))
+			Expect(conds[0].ObservedGeneration).To(Equal(int64(3)))
+			Expect(conds[0].LastTransitionTime.IsZero()).To(BeFalse())
+			Expect(FindMetaSliceStatus(conds, "Ready")).To(Equal(metav1.ConditionTrue))
+			Expect(FindMetaSliceStatus(conds, "Other")).To(Equal(metav1.ConditionUnknown))
+		})
+
+		It("should not update the slice if the condition would be invalid", func() {
+			conds := []metav1.Condition{cond}
+			Expect(UpdateMetaSlice(&conds, "Ready", UpdateReason("not-a-reason"))).NotTo(Succeed())
+			Expect(conds).To(Equal([]metav1.Condition{cond}))
+
+			Expect(UpdateMetaSlice(&conds, "Other", UpdateMetaStatus(metav1.ConditionTrue))).NotTo(Succeed())
+			Expect(conds).To(Equal([]metav1.Condition{cond}))
+		})
+	})
+
+	Describe("UpdateMeta", func() {
+		It("should not modify the condition if it would be invalid", func() {
+			updated := cond
+			Expect(UpdateMeta(&updated, UpdateMetaStatus("Maybe"))).NotTo(Succeed())
+			Expect(updated).To(Equal(cond))
+
+			MustUpdateMeta(&updated, UpdateMetaStatus(metav1.ConditionFalse), UpdateReason("NotReady"))
+			Expect(updated.Status).To(Equal(metav1.ConditionFalse))
+			Expect(updated.LastTransitionTime).NotTo(Equal(cond.LastTransitionTime))
+		})
+	})
+})
@@ -0,0 +1,163 @@
+// Copyright 2023 IronCore authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conditionutils
+
+import (
+	"fmt"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// MirrorReasonChildAbsent is the default reason of a mirrored condition if the child object is absent.
+	MirrorReasonChildAbsent = "ChildAbsent"
+	// MirrorReasonConditionMissing is the default reason of a mirrored condition if the child object
+	// does not have the source condition.
+	MirrorReasonConditionMissing = "ConditionMissing"
+)
+
+// Mirror describes how a condition of a child object is mirrored into a condition of its parent.
+type Mirror struct {
+	// SourceType is the type of the condition of the child object.
+	SourceType string
+	// TargetType is the type of the mirrored condition. If empty, SourceType is used.
+	TargetType string
+	// ConditionsPath is the field path of the conditions of the child object.
+	// If empty, DefaultConditionsPath is used.
+	ConditionsPath []string
+
+	// StatusMapping maps the status of the source condition. Statuses not contained are mirrored as-is.
+	StatusMapping map[corev1.ConditionStatus]corev1.ConditionStatus
+	// ReasonMapping maps the reason of the source condition. Reasons not contained are mirrored as-is.
+	ReasonMapping map[string]string
+
+	// AbsentReason is the reason of the mirrored condition if the child object is absent.
+	// If empty, MirrorReasonChildAbsent is used.
+	AbsentReason string
+	// MissingReason is the reason of the mirrored condition if the child object does not have
+	// the source condition. If empty, MirrorReasonConditionMissing is used.
+	MissingReason string
+}
+
+// Target returns the type of the mirrored condition.
+func (m Mirror) Target() string {
+	if m.TargetType != "" {
+		return m.TargetType
+	}
+	return m.SourceType
+}
+
+// MirrorResult is the computed mirrored condition. It implements TypedUpdateOption.
+type MirrorResult struct {
+	// Status is the status of the mirrored condition.
+	Status corev1.ConditionStatus
+	// Reason is the reason of the mirrored condition.
+	Reason string
+	// Message is the message of the mirrored condition.
+	Message string
+}
+
+// ApplyToCondition implements TypedUpdateOption.
+func (r MirrorResult) ApplyToCondition(w ConditionWriter) error {
+	w.SetStatus(r.Status)
+	w.SetReason(r.Reason)
+	w.SetMessage(r.Message)
+	return nil
+}
+
+func isNilObject(obj client.Object) bool {
+	if obj == nil {
+		return true
+	}
+	v := reflect.ValueOf(obj)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
+
+func objectContent(obj client.Object) (map[string]interface{}, error) {
+	if u, ok := obj.(runtime.Unstructured); ok {
+		return u.UnstructuredContent(), nil
+	}
+	return runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+}
+
+// ComputeMirror computes the condition mirrored from the given child object as described by the given Mirror.
+//
+// The child object may be typed or unstructured. If it is nil, the mirrored condition has an unknown status
+// and the absent reason. If the child object does not have the source condition, the mirrored condition
+// has an unknown status and the missing reason. Otherwise, status, reason and message of the source condition
+// are mirrored, applying the status and reason mappings.
+func ComputeMirror(child client.Object, m Mirror) (MirrorResult, error) {
+	if isNilObject(child) {
+		reason := m.AbsentReason
+		if reason == "" {
+			reason = MirrorReasonChildAbsent
+		}
+		return MirrorResult{
+			Status:  corev1.ConditionUnknown,
+			Reason:  reason,
+			Message: "Child object is absent.",
+		}, nil
+	}
+
+	content, err := objectContent(child)
+	if err != nil {
+		return MirrorResult{}, fmt.Errorf("error converting %T to unstructured: %w", child, err)
+	}
+
+	acc := NewUnstructuredAccessor(UnstructuredAccessorOptions{Path: m.ConditionsPath})
+	conds, err := acc.conditions(content)
+	if err != nil {
+		return MirrorResult{}, fmt.Errorf("error getting conditions: %w", err)
+	}
+
+	cond, ok := acc.FindSlice(conds, m.SourceType)
+	if !ok {
+		reason := m.MissingReason
+		if reason == "" {
+			reason = MirrorReasonConditionMissing
+		}
+		return MirrorResult{
+			Status:  corev1.ConditionUnknown,
+			Reason:  reason,
+			Message: fmt.Sprintf("Child object has no condition %s.", m.SourceType),
+		}, nil
+	}
+
+	res := MirrorResult{
+		Status:  acc.Status(cond),
+		Reason:  acc.Reason(cond),
+		Message: acc.Message(cond),
+	}
+	if mapped, ok := m.StatusMapping[res.Status]; ok {
+		res.Status = mapped
+	}
+	if mapped, ok := m.ReasonMapping[res.Reason]; ok {
+		res.Reason = mapped
+	}
+	return res, nil
+}
+
+// UpdateSliceMirror computes the condition mirrored from the given child object using ComputeMirror and
+// updates it in the given condition slice using TypedAccessor.UpdateSlice with the given additional options.
+func UpdateSliceMirror[C any](acc *TypedAccessor[C], conds *[]C, child client.Object, m Mirror, opts ...TypedUpdateOption) error {
+	res, err := ComputeMirror(child, m)
+	if err != nil {
+		return err
+	}
+	return acc.UpdateSlice(conds, m.Target(), append([]TypedUpdateOption{res}, opts...)...)
+}
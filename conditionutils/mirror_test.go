@@ -0,0 +1,129 @@
+// Copyright 2023 IronCore authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conditionutils_test
+
+import (
+	. "github.com/ironcore-dev/controller-utils/conditionutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var _ = Describe("Mirror", func() {
+	var (
+		deployment *appsv1.Deployment
+		mirror     Mirror
+	)
+	BeforeEach(func() {
+		deployment = &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: corev1.NamespaceDefault, Name: "my-deployment"},
+			Status: appsv1.DeploymentStatus{
+				Conditions: []appsv1.DeploymentCondition{
+					{
+						Type:    appsv1.DeploymentAvailable,
+						Status:  corev1.ConditionTrue,
+						Reason:  "MinimumReplicasAvailable",
+						Message: "Deployment has minimum availability.",
+					},
+				},
+			},
+		}
+		mirror = Mirror{
+			SourceType: string(appsv1.DeploymentAvailable),
+			TargetType: "DeploymentAvailable",
+		}
+	})
+
+	Describe("ComputeMirror", func() {
+		It("should mirror the condition of a typed object", func() {
+			Expect(ComputeMirror(deployment, mirror)).To(Equal(MirrorResult{
+				Status:  corev1.ConditionTrue,
+				Reason:  "MinimumReplicasAvailable",
+				Message: "Deployment has minimum availability.",
+			}))
+		})
+
+		It("should mirror the condition of an unstructured object at a custom path", func() {
+			u := &unstructured.Unstructured{Object: map[string]interface{}{
+				"status": map[string]interface{}{
+					"health": map[string]interface{}{
+						"conditions": []interface{}{
+							map[string]interface{}{"type": "Healthy", "status": "False", "reason": "Unhealthy"},
+						},
+					},
+				},
+			}}
+
+			Expect(ComputeMirror(u, Mirror{
+				SourceType:     "Healthy",
+				ConditionsPath: []string{"status", "health", "conditions"},
+			})).To(Equal(MirrorResult{
+				Status: corev1.ConditionFalse,
+				Reason: "Unhealthy",
+			}))
+		})
+
+		It("should apply the status and reason mappings", func() {
+			mirror.StatusMapping = map[corev1.ConditionStatus]corev1.ConditionStatus{
+				corev1.ConditionTrue:  corev1.ConditionFalse,
+				corev1.ConditionFalse: corev1.ConditionTrue,
+			}
+			mirror.ReasonMapping = map[string]string{"MinimumReplicasAvailable": "Available"}
+
+			res, err := ComputeMirror(deployment, mirror)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.Status).To(Equal(corev1.ConditionFalse))
+			Expect(res.Reason).To(Equal("Available"))
+		})
+
+		It("should report an unknown status if the child or its condition is absent", func() {
+			var absent *appsv1.Deployment
+			res, err := ComputeMirror(absent, mirror)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.Status).To(Equal(corev1.ConditionUnknown))
+			Expect(res.Reason).To(Equal(MirrorReasonChildAbsent))
+
+			deployment.Status.Conditions = nil
+			res, err = ComputeMirror(deployment, mirror)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.Status).To(Equal(corev1.ConditionUnknown))
+			Expect(res.Reason).To(Equal(MirrorReasonConditionMissing))
+		})
+
+		It("should error if the conditions are malformed", func() {
+			u := &unstructured.Unstructured{Object: map[string]interface{}{
+				"status": map[string]interface{}{"conditions": "foo"},
+			}}
+			_, err := ComputeMirror(u, mirror)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("UpdateSliceMirror", func() {
+		It("should update the mirrored condition in the slice", func() {
+			var conds []metav1.Condition
+			Expect(UpdateSliceMirror(MetaConditionAccessor, &conds, deployment, mirror, UpdateObservedGeneration(3))).To(Succeed())
+
+			cond, ok := FindMetaSlice(conds, "DeploymentAvailable")
+			Expect(ok).To(BeTrue())
+			Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+			Expect(cond.Reason).To(Equal("MinimumReplicasAvailable"))
+			Expect(cond.ObservedGeneration).To(Equal(int64(3)))
+		})
+	})
+})
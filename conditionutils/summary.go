@@ -0,0 +1,226 @@
+// Copyright 2023 IronCore authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conditionutils
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// SummaryReasonAsExpected is the default reason of a summary condition if no contributing condition is offending.
+	SummaryReasonAsExpected = "AsExpected"
+	// SummaryReasonUnknown is the reason of a summary condition if the first offending condition has no reason,
+	// e.g. because it is missing.
+	SummaryReasonUnknown = "Unknown"
+)
+
+// Polarity specifies which status of a condition is considered good.
+type Polarity int
+
+const (
+	// PolarityPositive conditions are good if their status is True, e.g. 'Ready' or 'Available'.
+	PolarityPositive Polarity = iota
+	// PolarityNegative conditions are good if their status is False, e.g. 'Degraded' or 'Stalled'.
+	PolarityNegative
+)
+
+// SummaryStrategy specifies how the status of a summary condition is computed from its contributing conditions.
+type SummaryStrategy int
+
+const (
+	// SummaryAllTrue makes the summary True if all contributing conditions are good, otherwise False.
+	// All contributing conditions that are not good are offending.
+	SummaryAllTrue SummaryStrategy = iota
+	// SummaryAnyFalse makes the summary False if any contributing condition is bad, otherwise True.
+	// Contributing conditions with unknown status do not affect the summary.
+	SummaryAnyFalse
+	// SummaryWorstSeverity makes the summary False if any contributing condition is bad, Unknown if any
+	// contributing condition is unknown and True otherwise. Only the conditions of the worst severity are offending.
+	SummaryWorstSeverity
+)
+
+// SummaryContributor is a condition type contributing to a summary condition.
+type SummaryContributor struct {
+	// Type is the type of the contributing condition.
+	Type string
+	// Polarity is the polarity of the contributing condition.
+	Polarity Polarity
+}
+
+// Summary describes a summary condition, e.g. 'Ready', computed from multiple contributing conditions.
+//
+// Contributing conditions that are missing are considered to have an unknown status.
+type Summary struct {
+	// Type is the type of the summary condition.
+	Type string
+	// Contributors are the contributing condition types. The first offending contributor determines
+	// the reason of the summary condition.
+	Contributors []SummaryContributor
+	// Strategy is the SummaryStrategy to compute the summary status with.
+	Strategy SummaryStrategy
+	// Reason is the reason of the summary condition if no contributing condition is offending.
+	// If empty, SummaryReasonAsExpected is used.
+	Reason string
+	// Message is the message of the summary condition if no contributing condition is offending.
+	Message string
+}
+
+type severity int
+
+const (
+	severityGood severity = iota
+	severityUnknown
+	severityBad
+)
+
+func (p Polarity) severity(status corev1.ConditionStatus) severity {
+	goodStatus, badStatus := corev1.ConditionTrue, corev1.ConditionFalse
+	if p == PolarityNegative {
+		goodStatus, badStatus = badStatus, goodStatus
+	}
+
+	switch status {
+	case goodStatus:
+		return severityGood
+	case badStatus:
+		return severityBad
+	default:
+		return severityUnknown
+	}
+}
+
+// SummaryResult is the computed summary condition. It implements TypedUpdateOption.
+type SummaryResult struct {
+	// Status is the status of the summary condition.
+	Status corev1.ConditionStatus
+	// Reason is the reason of the summary condition.
+	Reason string
+	// Message is the message of the summary condition.
+	Message string
+	// Offending are the types of the offending contributing conditions.
+	Offending []string
+}
+
+// ApplyToCondition implements TypedUpdateOption.
+func (r SummaryResult) ApplyToCondition(w ConditionWriter) error {
+	w.SetStatus(r.Status)
+	w.SetReason(r.Reason)
+	w.SetMessage(r.Message)
+	return nil
+}
+
+type contribution[C any] struct {
+	typ      string
+	cond     C
+	found    bool
+	severity severity
+}
+
+// ComputeSummary computes the summary condition described by the given Summary from the given conditions.
+//
+// If any contributing condition is offending, the reason of the first offending condition is used as reason
+// of the summary. The message of the summary lists the messages of all offending conditions.
+func ComputeSummary[C any](acc *TypedAccessor[C], conds []C, s Summary) SummaryResult {
+	worst := severityGood
+	contributions := make([]contribution[C], 0, len(s.Contributors))
+	for _, contributor := range s.Contributors {
+		c := contribution[C]{typ: contributor.Type}
+		c.cond, c.found = acc.FindSlice(conds, contributor.Type)
+		if c.found {
+			c.severity = contributor.Polarity.severity(acc.Status(c.cond))
+		} else {
+			c.severity = severityUnknown
+		}
+		if c.severity > worst {
+			worst = c.severity
+		}
+		contributions = append(contributions, c)
+	}
+
+	var (
+		status    corev1.ConditionStatus
+		offending func(c contribution[C]) bool
+	)
+	switch s.Strategy {
+	case SummaryAnyFalse:
+		status = corev1.ConditionTrue
+		if worst == severityBad {
+			status = corev1.ConditionFalse
+		}
+		offending = func(c contribution[C]) bool { return c.severity == severityBad }
+	case SummaryWorstSeverity:
+		status = map[severity]corev1.ConditionStatus{
+			severityGood:    corev1.ConditionTrue,
+			severityUnknown: corev1.ConditionUnknown,
+			severityBad:     corev1.ConditionFalse,
+		}[worst]
+		offending = func(c contribution[C]) bool { return c.severity != severityGood && c.severity == worst }
+	default:
+		status = corev1.ConditionTrue
+		if worst != severityGood {
+			status = corev1.ConditionFalse
+		}
+		offending = func(c contribution[C]) bool { return c.severity != severityGood }
+	}
+
+	res := SummaryResult{Status: status}
+	var messages []string
+	for _, c := range contributions {
+		if !offending(c) {
+			continue
+		}
+
+		if len(res.Offending) == 0 && c.found {
+			res.Reason = acc.Reason(c.cond)
+		}
+		res.Offending = append(res.Offending, c.typ)
+		messages = append(messages, contributionMessage(acc, c))
+	}
+
+	if len(res.Offending) == 0 {
+		res.Reason = s.Reason
+		if res.Reason == "" {
+			res.Reason = SummaryReasonAsExpected
+		}
+		res.Message = s.Message
+		return res
+	}
+
+	if res.Reason == "" {
+		res.Reason = SummaryReasonUnknown
+	}
+	res.Message = strings.Join(messages, "; ")
+	return res
+}
+
+func contributionMessage[C any](acc *TypedAccessor[C], c contribution[C]) string {
+	if !c.found {
+		return fmt.Sprintf("%s: condition is missing", c.typ)
+	}
+	if message := acc.Message(c.cond); message != "" {
+		return fmt.Sprintf("%s: %s", c.typ, message)
+	}
+	return fmt.Sprintf("%s: status is %s", c.typ, acc.Status(c.cond))
+}
+
+// UpdateSliceSummary computes the summary condition described by the given Summary using ComputeSummary and
+// updates it in the given condition slice using TypedAccessor.UpdateSlice with the given additional options.
+func UpdateSliceSummary[C any](acc *TypedAccessor[C], conds *[]C, s Summary, opts ...TypedUpdateOption) error {
+	res := ComputeSummary(acc, *conds, s)
+	return acc.UpdateSlice(conds, s.Type, append([]TypedUpdateOption{res}, opts...)...)
+}
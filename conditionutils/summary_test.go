@@ -0,0 +1,136 @@
+// Copyright 2023 IronCore authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conditionutils_test
+
+import (
+	. "github.com/ironcore-dev/controller-utils/conditionutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Summary", func() {
+	cond := func(typ string, status metav1.ConditionStatus, reason, message string) metav1.Condition {
+		return metav1.Condition{
+			Type:               typ,
+			Status:             status,
+			LastTransitionTime: metav1.Unix(1, 0),
+			Reason:             reason,
+			Message:            message,
+		}
+	}
+
+	contributors := []SummaryContributor{
+		{Type: "Available"},
+		{Type: "Synced"},
+		{Type: "Degraded", Polarity: PolarityNegative},
+	}
+
+	var (
+		available, synced, notDegraded metav1.Condition
+	)
+	BeforeEach(func() {
+		available = cond("Available", metav1.ConditionTrue, "Available", "")
+		synced = cond("Synced", metav1.ConditionTrue, "Synced", "")
+		notDegraded = cond("Degraded", metav1.ConditionFalse, "NotDegraded", "")
+	})
+
+	Describe("ComputeSummary", func() {
+		It("should be true with the summary reason if all contributing conditions are good", func() {
+			res := ComputeSummary(MetaConditionAccessor, []metav1.Condition{available, synced, notDegraded}, Summary{
+				Type:         "Ready",
+				Contributors: contributors,
+				Message:      "Everything is fine.",
+			})
+			Expect(res).To(Equal(SummaryResult{
+				Status:  corev1.ConditionTrue,
+				Reason:  SummaryReasonAsExpected,
+				Message: "Everything is fine.",
+			}))
+		})
+
+		It("should use the reason and message of the offending conditions for all-true", func() {
+			degraded := cond("Degraded", metav1.ConditionTrue, "TooManyRestarts", "Pod restarted 5 times.")
+			res := ComputeSummary(MetaConditionAccessor, []metav1.Condition{available, degraded}, Summary{
+				Type:         "Ready",
+				Contributors: contributors,
+				Strategy:     SummaryAllTrue,
+			})
+			Expect(res).To(Equal(SummaryResult{
+				Status:    corev1.ConditionFalse,
+				Reason:    SummaryReasonUnknown,
+				Message:   "Synced: condition is missing; Degraded: Pod restarted 5 times.",
+				Offending: []string{"Synced", "Degraded"},
+			}))
+		})
+
+		It("should ignore unknown conditions for any-false", func() {
+			unknownSynced := cond("Synced", metav1.ConditionUnknown, "Syncing", "")
+			summary := Summary{Type: "Ready", Contributors: contributors, Strategy: SummaryAnyFalse}
+
+			res := ComputeSummary(MetaConditionAccessor, []metav1.Condition{available, unknownSynced, notDegraded}, summary)
+			Expect(res.Status).To(Equal(corev1.ConditionTrue))
+			Expect(res.Offending).To(BeEmpty())
+
+			unavailable := cond("Available", metav1.ConditionFalse, "NoReplicas", "")
+			res = ComputeSummary(MetaConditionAccessor, []metav1.Condition{unavailable, unknownSynced, notDegraded}, summary)
+			Expect(res).To(Equal(SummaryResult{
+				Status:    corev1.ConditionFalse,
+				Reason:    "NoReplicas",
+				Message:   "Available: status is False",
+				Offending: []string{"Available"},
+			}))
+		})
+
+		It("should report the worst severity for worst-severity", func() {
+			unknownSynced := cond("Synced", metav1.ConditionUnknown, "Syncing", "Sync in progress.")
+			summary := Summary{Type: "Ready", Contributors: contributors, Strategy: SummaryWorstSeverity}
+
+			res := ComputeSummary(MetaConditionAccessor, []metav1.Condition{available, unknownSynced, notDegraded}, summary)
+			Expect(res).To(Equal(SummaryResult{
+				Status:    corev1.ConditionUnknown,
+				Reason:    "Syncing",
+				Message:   "Synced: Sync in progress.",
+				Offending: []string{"Synced"},
+			}))
+
+			degraded := cond("Degraded", metav1.ConditionTrue, "TooManyRestarts", "")
+			res = ComputeSummary(MetaConditionAccessor, []metav1.Condition{available, unknownSynced, degraded}, summary)
+			Expect(res).To(Equal(SummaryResult{
+				Status:    corev1.ConditionFalse,
+				Reason:    "TooManyRestarts",
+				Message:   "Degraded: status is True",
+				Offending: []string{"Degraded"},
+			}))
+		})
+	})
+
+	Describe("UpdateSliceSummary", func() {
+		It("should update the summary condition in the slice", func() {
+			conds := []metav1.Condition{available, notDegraded}
+			Expect(UpdateSliceSummary(MetaConditionAccessor, &conds, Summary{
+				Type:         "Ready",
+				Contributors: contributors[:1],
+			}, UpdateObservedGeneration(2))).To(Succeed())
+
+			ready, ok := FindMetaSlice(conds, "Ready")
+			Expect(ok).To(BeTrue())
+			Expect(ready.Status).To(Equal(metav1.ConditionTrue))
+			Expect(ready.Reason).To(Equal(SummaryReasonAsExpected))
+			Expect(ready.ObservedGeneration).To(Equal(int64(2)))
+		})
+	})
+})
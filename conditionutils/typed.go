@@ -0,0 +1,337 @@
+// Copyright 2023 IronCore authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conditionutils
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/utils/clock"
+)
+
+// ConditionWriter writes the fields of a single condition.
+//
+// It is the common interface TypedUpdateOption options are applied to, regardless of how a condition is stored.
+// Writing a field the condition does not have is a no-op.
+type ConditionWriter interface {
+	// SetStatus sets the status of the condition.
+	SetStatus(status corev1.ConditionStatus)
+	// SetReason sets the reason of the condition.
+	SetReason(reason string)
+	// SetMessage sets the message of the condition.
+	SetMessage(message string)
+	// SetObservedGeneration sets the observed generation of the condition.
+	SetObservedGeneration(gen int64)
+}
+
+// TypedUpdateOption is an option given to TypedAccessor.Update and TypedAccessor.UpdateSlice.
+//
+// UpdateStatus, UpdateReason, UpdateMessage and UpdateObservedGeneration implement TypedUpdateOption.
+type TypedUpdateOption interface {
+	// ApplyToCondition applies the update to the given ConditionWriter.
+	ApplyToCondition(w ConditionWriter) error
+}
+
+// TypedTransition determines whether a condition transitioned from old to new.
+type TypedTransition[C any] func(acc *TypedAccessor[C], old, new C) bool
+
+// TypedFieldsTransition returns a TypedTransition that computes whether a condition transitioned
+// using the `Include`-Fields of the given FieldsTransition.
+func TypedFieldsTransition[C any](t FieldsTransition) TypedTransition[C] {
+	return func(acc *TypedAccessor[C], old, new C) bool {
+		return (t.IncludeStatus && acc.Status(old) != acc.Status(new)) ||
+			(t.IncludeReason && acc.Reason(old) != acc.Reason(new)) ||
+			(t.IncludeMessage && acc.Message(old) != acc.Message(new))
+	}
+}
+
+// TypedAccessorOptions are options to create a TypedAccessor for conditions of type C.
+//
+// The getter and setter functions for type and status are required. All other getter / setter functions
+// are optional. If unset, the condition type is considered not to have the respective field.
+type TypedAccessorOptions[C any] struct {
+	GetType   func(cond C) string
+	SetType   func(cond *C, typ string)
+	GetStatus func(cond C) corev1.ConditionStatus
+	SetStatus func(cond *C, status corev1.ConditionStatus)
+
+	GetReason             func(cond C) string
+	SetReason             func(cond *C, reason string)
+	GetMessage            func(cond C) string
+	SetMessage            func(cond *C, message string)
+	GetLastUpdateTime     func(cond C) metav1.Time
+	SetLastUpdateTime     func(cond *C, t metav1.Time)
+	GetLastTransitionTime func(cond C) metav1.Time
+	SetLastTransitionTime func(cond *C, t metav1.Time)
+	GetObservedGeneration func(cond C) int64
+	SetObservedGeneration func(cond *C, gen int64)
+
+	DisableTimestampUpdates bool
+	Transition              TypedTransition[C]
+	Clock                   clock.Clock
+
+	// Validate validates a condition after it has been updated. If it errors, the update is not applied.
+	Validate func(cond C) error
+	// DeepCopy copies a condition before it is updated. It is required if C is a reference type, e.g. a map,
+	// as otherwise failed updates modify the original condition. If it errors, the update is not applied.
+	DeepCopy func(cond C) (C, error)
+}
+
+// SetDefaults sets default values for TypedAccessorOptions.
+func (o *TypedAccessorOptions[C]) SetDefaults() {
+	if o.Transition == nil {
+		o.Transition = TypedFieldsTransition[C](FieldsTransition{IncludeStatus: true})
+	}
+	if o.Clock == nil {
+		o.Clock = clock.RealClock{}
+	}
+}
+
+// TypedAccessor allows getting and setting fields of conditions of type C using typed getter and setter functions.
+//
+// Unlike Accessor, it does not use reflection, so inaccessible fields are detected at compile time.
+// It supports the same complex manipulations on individual conditions and condition slices.
+type TypedAccessor[C any] struct {
+	opts TypedAccessorOptions[C]
+}
+
+// NewTypedAccessor creates a new TypedAccessor with the given TypedAccessorOptions.
+// It panics if any of the required getter / setter functions is unset.
+func NewTypedAccessor[C any](opts TypedAccessorOptions[C]) *TypedAccessor[C] {
+	if opts.GetType == nil || opts.SetType == nil || opts.GetStatus == nil || opts.SetStatus == nil {
+		panic("conditionutils: type and status getter and setter functions are required")
+	}
+
+	opts.SetDefaults()
+	return &TypedAccessor[C]{opts: opts}
+}
+
+// Type returns the type of the given condition.
+func (a *TypedAccessor[C]) Type(cond C) string {
+	return a.opts.GetType(cond)
+}
+
+// Status returns the status of the given condition.
+func (a *TypedAccessor[C]) Status(cond C) corev1.ConditionStatus {
+	return a.opts.GetStatus(cond)
+}
+
+// Reason returns the reason of the given condition or an empty string if the condition has no reason.
+func (a *TypedAccessor[C]) Reason(cond C) string {
+	if a.opts.GetReason == nil {
+		return ""
+	}
+	return a.opts.GetReason(cond)
+}
+
+// Message returns the message of the given condition or an empty string if the condition has no message.
+func (a *TypedAccessor[C]) Message(cond C) string {
+	if a.opts.GetMessage == nil {
+		return ""
+	}
+	return a.opts.GetMessage(cond)
+}
+
+// HasLastUpdateTime reports whether conditions of type C have a last update time.
+func (a *TypedAccessor[C]) HasLastUpdateTime() bool {
+	return a.opts.GetLastUpdateTime != nil
+}
+
+// LastUpdateTime returns the last update time of the given condition or the zero time
+// if the condition has no last update time.
+func (a *TypedAccessor[C]) LastUpdateTime(cond C) metav1.Time {
+	if a.opts.GetLastUpdateTime == nil {
+		return metav1.Time{}
+	}
+	return a.opts.GetLastUpdateTime(cond)
+}
+
+// HasLastTransitionTime reports whether conditions of type C have a last transition time.
+func (a *TypedAccessor[C]) HasLastTransitionTime() bool {
+	return a.opts.GetLastTransitionTime != nil
+}
+
+// LastTransitionTime returns the last transition time of the given condition or the zero time
+// if the condition has no last transition time.
+func (a *TypedAccessor[C]) LastTransitionTime(cond C) metav1.Time {
+	if a.opts.GetLastTransitionTime == nil {
+		return metav1.Time{}
+	}
+	return a.opts.GetLastTransitionTime(cond)
+}
+
+// HasObservedGeneration reports whether conditions of type C have an observed generation.
+func (a *TypedAccessor[C]) HasObservedGeneration() bool {
+	return a.opts.GetObservedGeneration != nil
+}
+
+// ObservedGeneration returns the observed generation of the given condition or zero if the condition
+// has no observed generation.
+func (a *TypedAccessor[C]) ObservedGeneration(cond C) int64 {
+	if a.opts.GetObservedGeneration == nil {
+		return 0
+	}
+	return a.opts.GetObservedGeneration(cond)
+}
+
+// typedConditionWriter is the ConditionWriter of a condition of type C.
+type typedConditionWriter[C any] struct {
+	opts *TypedAccessorOptions[C]
+	cond *C
+}
+
+func (w typedConditionWriter[C]) SetStatus(status corev1.ConditionStatus) {
+	w.opts.SetStatus(w.cond, status)
+}
+
+func (w typedConditionWriter[C]) SetReason(reason string) {
+	if w.opts.SetReason != nil {
+		w.opts.SetReason(w.cond, reason)
+	}
+}
+
+func (w typedConditionWriter[C]) SetMessage(message string) {
+	if w.opts.SetMessage != nil {
+		w.opts.SetMessage(w.cond, message)
+	}
+}
+
+func (w typedConditionWriter[C]) SetObservedGeneration(gen int64) {
+	if w.opts.SetObservedGeneration != nil {
+		w.opts.SetObservedGeneration(w.cond, gen)
+	}
+}
+
+// Writer returns a ConditionWriter for the given condition.
+func (a *TypedAccessor[C]) Writer(cond *C) ConditionWriter {
+	return typedConditionWriter[C]{opts: &a.opts, cond: cond}
+}
+
+// Update updates the condition with the given options, setting transition- and update time accordingly.
+//
+// Update errors if any of the options errors or the updated condition is invalid.
+// In that case, the condition is not modified.
+func (a *TypedAccessor[C]) Update(cond *C, opts ...TypedUpdateOption) error {
+	updated := *cond
+	if a.opts.DeepCopy != nil {
+		var err error
+		if updated, err = a.opts.DeepCopy(*cond); err != nil {
+			return err
+		}
+	}
+
+	w := a.Writer(&updated)
+	for _, opt := range opts {
+		if err := opt.ApplyToCondition(w); err != nil {
+			return err
+		}
+	}
+
+	if !a.opts.DisableTimestampUpdates {
+		now := metav1.NewTime(a.opts.Clock.Now())
+		if a.opts.SetLastTransitionTime != nil && a.opts.Transition(a, *cond, updated) {
+			a.opts.SetLastTransitionTime(&updated, now)
+		}
+		if a.opts.SetLastUpdateTime != nil {
+			a.opts.SetLastUpdateTime(&updated, now)
+		}
+	}
+
+	if a.opts.Validate != nil {
+		if err := a.opts.Validate(updated); err != nil {
+			return err
+		}
+	}
+
+	*cond = updated
+	return nil
+}
+
+// MustUpdate updates the condition with the given options, setting transition- and update time accordingly.
+//
+// MustUpdate panics if any of the options errors or the updated condition is invalid.
+func (a *TypedAccessor[C]) MustUpdate(cond *C, opts ...TypedUpdateOption) {
+	utilruntime.Must(a.Update(cond, opts...))
+}
+
+// FindSliceIndex finds the index of the condition with the given type.
+// If the target type is not found, -1 is returned.
+func (a *TypedAccessor[C]) FindSliceIndex(conds []C, typ string) int {
+	for i, cond := range conds {
+		if a.opts.GetType(cond) == typ {
+			return i
+		}
+	}
+	return -1
+}
+
+// FindSlice finds the condition with the given type in the given slice.
+// If the target type is not found, false is returned.
+func (a *TypedAccessor[C]) FindSlice(conds []C, typ string) (C, bool) {
+	idx := a.FindSliceIndex(conds, typ)
+	if idx == -1 {
+		var zero C
+		return zero, false
+	}
+	return conds[idx], true
+}
+
+// FindSliceStatus finds the status of the condition with the given type.
+// If the condition cannot be found, corev1.ConditionUnknown is returned.
+func (a *TypedAccessor[C]) FindSliceStatus(conds []C, typ string) corev1.ConditionStatus {
+	cond, ok := a.FindSlice(conds, typ)
+	if !ok {
+		return corev1.ConditionUnknown
+	}
+	return a.opts.GetStatus(cond)
+}
+
+// UpdateSlice finds and updates the condition with the given target type.
+//
+// If no condition with the given type can be found, a new one is appended with the given type and updates
+// applied. For new conditions, the last transition time is always set to the current time while for existing
+// conditions, it's checked whether the condition transitioned.
+// If any of the options errors or the updated condition is invalid, the slice is not modified.
+func (a *TypedAccessor[C]) UpdateSlice(conds *[]C, typ string, opts ...TypedUpdateOption) error {
+	var cond C
+	idx := a.FindSliceIndex(*conds, typ)
+	if idx != -1 {
+		cond = (*conds)[idx]
+	} else {
+		a.opts.SetType(&cond, typ)
+		if a.opts.SetLastTransitionTime != nil {
+			a.opts.SetLastTransitionTime(&cond, metav1.NewTime(a.opts.Clock.Now()))
+		}
+	}
+
+	if err := a.Update(&cond, opts...); err != nil {
+		return err
+	}
+
+	if idx != -1 {
+		(*conds)[idx] = cond
+	} else {
+		*conds = append(*conds, cond)
+	}
+	return nil
+}
+
+// MustUpdateSlice finds and updates the condition with the given target type.
+//
+// MustUpdateSlice panics if any of the options errors or the updated condition is invalid.
+// See UpdateSlice for more.
+func (a *TypedAccessor[C]) MustUpdateSlice(conds *[]C, typ string, opts ...TypedUpdateOption) {
+	utilruntime.Must(a.UpdateSlice(conds, typ, opts...))
+}
@@ -0,0 +1,178 @@
+// Copyright 2023 IronCore authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conditionutils_test
+
+import (
+	"fmt"
+	"time"
+
+	. "github.com/ironcore-dev/controller-utils/conditionutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clock "k8s.io/utils/clock/testing"
+)
+
+type failingUpdate struct{}
+
+func (failingUpdate) ApplyToCondition(ConditionWriter) error {
+	return fmt.Errorf("failing update")
+}
+
+var _ = Describe("TypedAccessor", func() {
+	var (
+		now     time.Time
+		metaNow metav1.Time
+		c       *clock.FakeClock
+		acc     *TypedAccessor[appsv1.DeploymentCondition]
+
+		deployCond appsv1.DeploymentCondition
+	)
+	BeforeEach(func() {
+		now = time.Unix(100, 0)
+		metaNow = metav1.NewTime(now)
+		c = clock.NewFakeClock(now)
+		acc = NewTypedAccessor(TypedAccessorOptions[appsv1.DeploymentCondition]{
+			GetType: func(cond appsv1.DeploymentCondition) string { return string(cond.Type) },
+			SetType: func(cond *appsv1.DeploymentCondition, typ string) {
+				cond.Type = appsv1.DeploymentConditionType(typ)
+			},
+			GetStatus:             func(cond appsv1.DeploymentCondition) corev1.ConditionStatus { return cond.Status },
+			SetStatus:             func(cond *appsv1.DeploymentCondition, status corev1.ConditionStatus) { cond.Status = status },
+			GetReason:             func(cond appsv1.DeploymentCondition) string { return cond.Reason },
+			SetReason:             func(cond *appsv1.DeploymentCondition, reason string) { cond.Reason = reason },
+			GetMessage:            func(cond appsv1.DeploymentCondition) string { return cond.Message },
+			SetMessage:            func(cond *appsv1.DeploymentCondition, message string) { cond.Message = message },
+			GetLastUpdateTime:     func(cond appsv1.DeploymentCondition) metav1.Time { return cond.LastUpdateTime },
+			SetLastUpdateTime:     func(cond *appsv1.DeploymentCondition, t metav1.Time) { cond.LastUpdateTime = t },
+			GetLastTransitionTime: func(cond appsv1.DeploymentCondition) metav1.Time { return cond.LastTransitionTime },
+			SetLastTransitionTime: func(cond *appsv1.DeploymentCondition, t metav1.Time) { cond.LastTransitionTime = t },
+			Clock:                 c,
+		})
+
+		deployCond = appsv1.DeploymentCondition{
+			Type:               appsv1.DeploymentAvailable,
+			Status:             corev1.ConditionTrue,
+			LastUpdateTime:     metav1.Unix(2, 0),
+			LastTransitionTime: metav1.Unix(1, 0),
+			Reason:             "MinimumReplicasAvailable",
+			Message:            "ReplicaSet \"foo\" has successfully progressed.",
+		}
+	})
+
+	It("should panic if required functions are missing", func() {
+		Expect(func() {
+			NewTypedAccessor(TypedAccessorOptions[appsv1.DeploymentCondition]{})
+		}).To(Panic())
+	})
+
+	It("should get the fields of a condition", func() {
+		Expect(acc.Type(deployCond)).To(Equal(string(appsv1.DeploymentAvailable)))
+		Expect(acc.Status(deployCond)).To(Equal(corev1.ConditionTrue))
+		Expect(acc.Reason(deployCond)).To(Equal("MinimumReplicasAvailable"))
+		Expect(acc.Message(deployCond)).To(Equal("ReplicaSet \"foo\" has successfully progressed."))
+		Expect(acc.HasLastUpdateTime()).To(BeTrue())
+		Expect(acc.LastUpdateTime(deployCond)).To(Equal(metav1.Unix(2, 0)))
+		Expect(acc.HasLastTransitionTime()).To(BeTrue())
+		Expect(acc.LastTransitionTime(deployCond)).To(Equal(metav1.Unix(1, 0)))
+		Expect(acc.HasObservedGeneration()).To(BeFalse())
+		Expect(acc.ObservedGeneration(deployCond)).To(BeZero())
+	})
+
+	Describe("Update", func() {
+		It("should update the condition and its timestamps when it transitioned", func() {
+			Expect(acc.Update(&deployCond,
+				UpdateStatus(corev1.ConditionFalse),
+				UpdateReason("NotAvailable"),
+				UpdateMessage("not available"),
+				UpdateObservedGeneration(2),
+			)).To(Succeed())
+
+			Expect(deployCond).To(Equal(appsv1.DeploymentCondition{
+				Type:               appsv1.DeploymentAvailable,
+				Status:             corev1.ConditionFalse,
+				LastUpdateTime:     metaNow,
+				LastTransitionTime: metaNow,
+				Reason:             "NotAvailable",
+				Message:            "not available",
+			}))
+		})
+
+		It("should only update the last update time if the condition did not transition", func() {
+			Expect(acc.Update(&deployCond, UpdateMessage("still available"))).To(Succeed())
+
+			Expect(deployCond.LastUpdateTime).To(Equal(metaNow))
+			Expect(deployCond.LastTransitionTime).To(Equal(metav1.Unix(1, 0)))
+			Expect(deployCond.Message).To(Equal("still available"))
+		})
+
+		It("should return errors of update options", func() {
+			Expect(acc.Update(&deployCond, failingUpdate{})).To(MatchError("failing update"))
+			Expect(func() { acc.MustUpdate(&deployCond, failingUpdate{}) }).To(Panic())
+		})
+	})
+
+	Describe("FindSlice", func() {
+		It("should find conditions by type", func() {
+			conds := []appsv1.DeploymentCondition{deployCond}
+
+			Expect(acc.FindSliceIndex(conds, string(appsv1.DeploymentAvailable))).To(Equal(0))
+			Expect(acc.FindSliceIndex(conds, string(appsv1.DeploymentProgressing))).To(Equal(-1))
+
+			cond, ok := acc.FindSlice(conds, string(appsv1.DeploymentAvailable))
+			Expect(ok).To(BeTrue())
+			Expect(cond).To(Equal(deployCond))
+
+			_, ok = acc.FindSlice(conds, string(appsv1.DeploymentProgressing))
+			Expect(ok).To(BeFalse())
+
+			Expect(acc.FindSliceStatus(conds, string(appsv1.DeploymentAvailable))).To(Equal(corev1.ConditionTrue))
+			Expect(acc.FindSliceStatus(conds, string(appsv1.DeploymentProgressing))).To(Equal(corev1.ConditionUnknown))
+		})
+	})
+
+	Describe("UpdateSlice", func() {
+		It("should update an existing condition", func() {
+			conds := []appsv1.DeploymentCondition{deployCond}
+			Expect(acc.UpdateSlice(&conds, string(appsv1.DeploymentAvailable), UpdateStatus(corev1.ConditionFalse))).To(Succeed())
+
+			Expect(conds).To(HaveLen(1))
+			Expect(conds[0].Status).To(Equal(corev1.ConditionFalse))
+			Expect(conds[0].LastTransitionTime).To(Equal(metaNow))
+		})
+
+		It("should append a new condition", func() {
+			var conds []appsv1.DeploymentCondition
+			acc.MustUpdateSlice(&conds, string(appsv1.DeploymentProgressing), UpdateStatus(corev1.ConditionTrue))
+
+			Expect(conds).To(Equal([]appsv1.DeploymentCondition{
+				{
+					Type:               appsv1.DeploymentProgressing,
+					Status:             corev1.ConditionTrue,
+					LastUpdateTime:     metaNow,
+					LastTransitionTime: metaNow,
+				},
+			}))
+		})
+
+		It("should not modify the slice if an update errors", func() {
+			conds := []appsv1.DeploymentCondition{deployCond}
+			Expect(acc.UpdateSlice(&conds, string(appsv1.DeploymentAvailable), UpdateStatus(corev1.ConditionFalse), failingUpdate{})).NotTo(Succeed())
+			Expect(conds).To(Equal([]appsv1.DeploymentCondition{deployCond}))
+		})
+	})
+})
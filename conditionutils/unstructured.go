@@ -0,0 +1,363 @@
+// Copyright 2023 IronCore authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conditionutils
+
+import (
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/utils/clock"
+)
+
+const (
+	// DefaultUnstructuredTypeField is the default key of an unstructured condition's type field.
+	DefaultUnstructuredTypeField = "type"
+	// DefaultUnstructuredStatusField is the default key of an unstructured condition's status field.
+	DefaultUnstructuredStatusField = "status"
+	// DefaultUnstructuredLastUpdateTimeField is the conventional key of an unstructured condition's last update
+	// time field. As metav1.Condition does not have a last update time, it is not used unless configured.
+	DefaultUnstructuredLastUpdateTimeField = "lastUpdateTime"
+	// DefaultUnstructuredLastTransitionTimeField is the default key of an unstructured condition's last transition
+	// time field.
+	DefaultUnstructuredLastTransitionTimeField = "lastTransitionTime"
+	// DefaultUnstructuredReasonField is the default key of an unstructured condition's reason field.
+	DefaultUnstructuredReasonField = "reason"
+	// DefaultUnstructuredMessageField is the default key of an unstructured condition's message field.
+	DefaultUnstructuredMessageField = "message"
+	// DefaultUnstructuredObservedGenerationField is the default key of an unstructured condition's observed
+	// generation field.
+	DefaultUnstructuredObservedGenerationField = "observedGeneration"
+)
+
+// DefaultConditionsPath is the default field path of the conditions of an object.
+var DefaultConditionsPath = []string{"status", "conditions"}
+
+// UnstructuredAccessorOptions are options to create an UnstructuredAccessor.
+//
+// If left blank, defaults are being used via UnstructuredAccessorOptions.SetDefaults.
+type UnstructuredAccessorOptions struct {
+	// Path is the field path of the conditions inside an unstructured object.
+	Path []string
+
+	TypeField   string
+	StatusField string
+	// LastUpdateTimeField is the key of the last update time field. If empty, conditions are considered
+	// not to have a last update time and it is never written.
+	LastUpdateTimeField string
+	// LastTransitionTimeField is the key of the last transition time field.
+	// If empty, DefaultUnstructuredLastTransitionTimeField is used.
+	LastTransitionTimeField string
+	// DisableLastTransitionTime makes conditions be considered not to have a last transition time,
+	// so it is never written.
+	DisableLastTransitionTime bool
+	ReasonField               string
+	MessageField              string
+	ObservedGenerationField   string
+
+	DisableTimestampUpdates bool
+	Transition              TypedTransition[map[string]interface{}]
+	Clock                   clock.Clock
+}
+
+// SetDefaults sets default values for UnstructuredAccessorOptions.
+func (o *UnstructuredAccessorOptions) SetDefaults() {
+	if len(o.Path) == 0 {
+		o.Path = DefaultConditionsPath
+	}
+	if o.TypeField == "" {
+		o.TypeField = DefaultUnstructuredTypeField
+	}
+	if o.StatusField == "" {
+		o.StatusField = DefaultUnstructuredStatusField
+	}
+	if o.LastTransitionTimeField == "" {
+		o.LastTransitionTimeField = DefaultUnstructuredLastTransitionTimeField
+	}
+	if o.ReasonField == "" {
+		o.ReasonField = DefaultUnstructuredReasonField
+	}
+	if o.MessageField == "" {
+		o.MessageField = DefaultUnstructuredMessageField
+	}
+	if o.ObservedGenerationField == "" {
+		o.ObservedGenerationField = DefaultUnstructuredObservedGenerationField
+	}
+}
+
+// UnstructuredAccessor allows getting and setting fields of conditions stored as maps in unstructured objects,
+// e.g. for custom resources without Go types.
+//
+// It embeds a TypedAccessor for map[string]interface{} conditions, so it supports the same operations on
+// individual conditions and condition slices, including the same Transition semantics.
+// Additionally, it allows finding and updating conditions directly in an unstructured object.
+type UnstructuredAccessor struct {
+	*TypedAccessor[map[string]interface{}]
+	path []string
+}
+
+// NewUnstructuredAccessor creates a new UnstructuredAccessor with the given UnstructuredAccessorOptions.
+func NewUnstructuredAccessor(opts UnstructuredAccessorOptions) *UnstructuredAccessor {
+	opts.SetDefaults()
+	typedOpts := TypedAccessorOptions[map[string]interface{}]{
+		GetType:                 unstructuredStringGetter(opts.TypeField),
+		SetType:                 unstructuredSetter[string](opts.TypeField),
+		GetStatus:               unstructuredStatusGetter(opts.StatusField),
+		SetStatus:               unstructuredStatusSetter(opts.StatusField),
+		GetReason:               unstructuredStringGetter(opts.ReasonField),
+		SetReason:               unstructuredSetter[string](opts.ReasonField),
+		GetMessage:              unstructuredStringGetter(opts.MessageField),
+		SetMessage:              unstructuredSetter[string](opts.MessageField),
+		GetObservedGeneration:   unstructuredInt64Getter(opts.ObservedGenerationField),
+		SetObservedGeneration:   unstructuredSetter[int64](opts.ObservedGenerationField),
+		DisableTimestampUpdates: opts.DisableTimestampUpdates,
+		Transition:              opts.Transition,
+		Clock:                   opts.Clock,
+		DeepCopy:                deepCopyUnstructuredCondition,
+	}
+	if opts.LastUpdateTimeField != "" {
+		typedOpts.GetLastUpdateTime = unstructuredTimeGetter(opts.LastUpdateTimeField)
+		typedOpts.SetLastUpdateTime = unstructuredTimeSetter(opts.LastUpdateTimeField)
+	}
+	if !opts.DisableLastTransitionTime {
+		typedOpts.GetLastTransitionTime = unstructuredTimeGetter(opts.LastTransitionTimeField)
+		typedOpts.SetLastTransitionTime = unstructuredTimeSetter(opts.LastTransitionTimeField)
+	}
+
+	return &UnstructuredAccessor{
+		TypedAccessor: NewTypedAccessor(typedOpts),
+		path:          opts.Path,
+	}
+}
+
+func unstructuredStringGetter(field string) func(cond map[string]interface{}) string {
+	return func(cond map[string]interface{}) string {
+		s, _ := cond[field].(string)
+		return s
+	}
+}
+
+func unstructuredStatusGetter(field string) func(cond map[string]interface{}) corev1.ConditionStatus {
+	getString := unstructuredStringGetter(field)
+	return func(cond map[string]interface{}) corev1.ConditionStatus {
+		return corev1.ConditionStatus(getString(cond))
+	}
+}
+
+func unstructuredTimeGetter(field string) func(cond map[string]interface{}) metav1.Time {
+	getString := unstructuredStringGetter(field)
+	return func(cond map[string]interface{}) metav1.Time {
+		var t metav1.Time
+		_ = t.UnmarshalQueryParameter(getString(cond))
+		return t
+	}
+}
+
+func unstructuredInt64Getter(field string) func(cond map[string]interface{}) int64 {
+	return func(cond map[string]interface{}) int64 {
+		switch v := cond[field].(type) {
+		case int64:
+			return v
+		case int:
+			return int64(v)
+		case float64:
+			return int64(v)
+		default:
+			return 0
+		}
+	}
+}
+
+func setUnstructuredField(cond *map[string]interface{}, field string, value interface{}) {
+	if *cond == nil {
+		*cond = make(map[string]interface{})
+	}
+	(*cond)[field] = value
+}
+
+func unstructuredSetter[T string | int64](field string) func(cond *map[string]interface{}, value T) {
+	return func(cond *map[string]interface{}, value T) {
+		setUnstructuredField(cond, field, value)
+	}
+}
+
+func unstructuredStatusSetter(field string) func(cond *map[string]interface{}, status corev1.ConditionStatus) {
+	return func(cond *map[string]interface{}, status corev1.ConditionStatus) {
+		setUnstructuredField(cond, field, string(status))
+	}
+}
+
+func unstructuredTimeSetter(field string) func(cond *map[string]interface{}, t metav1.Time) {
+	return func(cond *map[string]interface{}, t metav1.Time) {
+		setUnstructuredField(cond, field, t.ToUnstructured())
+	}
+}
+
+// normalizeJSONValue deep copies the given value, converting numbers to the int64 / float64 representation
+// of unstructured objects. Unlike runtime.DeepCopyJSONValue, it errors instead of panicking on unsupported types.
+func normalizeJSONValue(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case nil, string, bool, int64, float64, json.Number:
+		return v, nil
+	case int:
+		return int64(v), nil
+	case int8:
+		return int64(v), nil
+	case int16:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case uint8:
+		return int64(v), nil
+	case uint16:
+		return int64(v), nil
+	case uint32:
+		return int64(v), nil
+	case float32:
+		return float64(v), nil
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			normalized, err := normalizeJSONValue(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			res[key] = normalized
+		}
+		return res, nil
+	case []interface{}:
+		res := make([]interface{}, len(v))
+		for i, value := range v {
+			normalized, err := normalizeJSONValue(value)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			res[i] = normalized
+		}
+		return res, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %T", v)
+	}
+}
+
+func deepCopyUnstructuredCondition(cond map[string]interface{}) (map[string]interface{}, error) {
+	if cond == nil {
+		return nil, nil
+	}
+	res, err := normalizeJSONValue(cond)
+	if err != nil {
+		return nil, fmt.Errorf("error copying condition: %w", err)
+	}
+	return res.(map[string]interface{}), nil
+}
+
+func (a *UnstructuredAccessor) conditions(content map[string]interface{}) ([]map[string]interface{}, error) {
+	// Avoid unstructured.NestedSlice as its deep copy panics on values like int that are valid in Go.
+	// Conditions are copied on update anyway.
+	value, found, err := unstructured.NestedFieldNoCopy(content, a.path...)
+	if err != nil || !found {
+		return nil, err
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%v accessor error: %v is of the type %T, expected []interface{}", a.path, value, value)
+	}
+
+	conds := make([]map[string]interface{}, 0, len(items))
+	for i, item := range items {
+		cond, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("condition %d is not an object but %T", i, item)
+		}
+		conds = append(conds, cond)
+	}
+	return conds, nil
+}
+
+// Conditions returns the conditions of the given unstructured object.
+// If the object has no conditions, an empty slice is returned.
+func (a *UnstructuredAccessor) Conditions(obj *unstructured.Unstructured) ([]map[string]interface{}, error) {
+	return a.conditions(obj.Object)
+}
+
+// SetConditions sets the conditions of the given unstructured object.
+//
+// Numbers in the conditions are converted to int64 / float64. SetConditions errors if any condition
+// contains values that cannot be represented in an unstructured object.
+func (a *UnstructuredAccessor) SetConditions(obj *unstructured.Unstructured, conds []map[string]interface{}) error {
+	items := make([]interface{}, 0, len(conds))
+	for i, cond := range conds {
+		item, err := deepCopyUnstructuredCondition(cond)
+		if err != nil {
+			return fmt.Errorf("condition %d: %w", i, err)
+		}
+		items = append(items, item)
+	}
+	if obj.Object == nil {
+		obj.Object = make(map[string]interface{})
+	}
+	return unstructured.SetNestedSlice(obj.Object, items, a.path...)
+}
+
+// FindObject finds the condition with the given type in the given unstructured object.
+// If the target type is not found, false is returned.
+func (a *UnstructuredAccessor) FindObject(obj *unstructured.Unstructured, typ string) (map[string]interface{}, bool, error) {
+	conds, err := a.Conditions(obj)
+	if err != nil {
+		return nil, false, err
+	}
+	cond, ok := a.FindSlice(conds, typ)
+	return cond, ok, nil
+}
+
+// FindObjectStatus finds the status of the condition with the given type in the given unstructured object.
+// If the condition cannot be found, corev1.ConditionUnknown is returned.
+func (a *UnstructuredAccessor) FindObjectStatus(obj *unstructured.Unstructured, typ string) (corev1.ConditionStatus, error) {
+	conds, err := a.Conditions(obj)
+	if err != nil {
+		return "", err
+	}
+	return a.FindSliceStatus(conds, typ), nil
+}
+
+// UpdateObject finds and updates the condition with the given target type in the given unstructured object.
+//
+// If any of the options errors or the updated condition is invalid, the object is not modified.
+// See TypedAccessor.UpdateSlice for more.
+func (a *UnstructuredAccessor) UpdateObject(obj *unstructured.Unstructured, typ string, opts ...TypedUpdateOption) error {
+	conds, err := a.Conditions(obj)
+	if err != nil {
+		return err
+	}
+	if err := a.UpdateSlice(&conds, typ, opts...); err != nil {
+		return err
+	}
+	return a.SetConditions(obj, conds)
+}
+
+// MustUpdateObject finds and updates the condition with the given target type in the given unstructured object.
+//
+// MustUpdateObject panics if the conditions are malformed, any of the options errors or the updated condition
+// is invalid. See UpdateObject for more.
+func (a *UnstructuredAccessor) MustUpdateObject(obj *unstructured.Unstructured, typ string, opts ...TypedUpdateOption) {
+	utilruntime.Must(a.UpdateObject(obj, typ, opts...))
+}
+
+// DefaultUnstructuredAccessor is an UnstructuredAccessor initialized with the default options.
+// See NewUnstructuredAccessor for more.
+var DefaultUnstructuredAccessor = NewUnstructuredAccessor(UnstructuredAccessorOptions{})
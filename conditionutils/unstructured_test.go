@@ -0,0 +1,174 @@
+// Copyright 2023 IronCore authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conditionutils_test
+
+import (
+	"time"
+
+	. "github.com/ironcore-dev/controller-utils/conditionutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clock "k8s.io/utils/clock/testing"
+)
+
+var _ = Describe("UnstructuredAccessor", func() {
+	var (
+		now     time.Time
+		metaNow metav1.Time
+		acc     *UnstructuredAccessor
+
+		cond map[string]interface{}
+		obj  *unstructured.Unstructured
+	)
+	BeforeEach(func() {
+		now = time.Unix(100, 0)
+		metaNow = metav1.NewTime(now)
+		acc = NewUnstructuredAccessor(UnstructuredAccessorOptions{Clock: clock.NewFakeClock(now)})
+
+		cond = map[string]interface{}{
+			"type":               "Ready",
+			"status":             "True",
+			"lastTransitionTime": "1970-01-01T00:00:01Z",
+			"reason":             "AsExpected",
+			"message":            "Everything is fine.",
+			"observedGeneration": int64(2),
+		}
+		obj = &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "example.org/v1alpha1",
+			"kind":       "Widget",
+			"status": map[string]interface{}{
+				"conditions": []interface{}{cond},
+			},
+		}}
+	})
+
+	It("should get the fields of a condition", func() {
+		Expect(acc.Type(cond)).To(Equal("Ready"))
+		Expect(acc.Status(cond)).To(Equal(corev1.ConditionTrue))
+		Expect(acc.Reason(cond)).To(Equal("AsExpected"))
+		Expect(acc.Message(cond)).To(Equal("Everything is fine."))
+		Expect(acc.LastTransitionTime(cond).Time).To(BeTemporally("==", time.Unix(1, 0)))
+		Expect(acc.LastUpdateTime(cond)).To(BeZero())
+		Expect(acc.ObservedGeneration(cond)).To(Equal(int64(2)))
+	})
+
+	Describe("Update", func() {
+		It("should update the condition and its last transition time when it transitioned", func() {
+			Expect(acc.Update(&cond,
+				UpdateStatus(corev1.ConditionFalse),
+				UpdateReason("Broken"),
+				UpdateObservedGeneration(3),
+			)).To(Succeed())
+
+			Expect(acc.Status(cond)).To(Equal(corev1.ConditionFalse))
+			Expect(acc.Reason(cond)).To(Equal("Broken"))
+			Expect(acc.ObservedGeneration(cond)).To(Equal(int64(3)))
+			Expect(acc.LastTransitionTime(cond).Time).To(BeTemporally("==", metaNow.Time))
+			Expect(cond).NotTo(HaveKey("lastUpdateTime"))
+		})
+
+		It("should only write the configured timestamp fields", func() {
+			acc := NewUnstructuredAccessor(UnstructuredAccessorOptions{
+				LastUpdateTimeField:       DefaultUnstructuredLastUpdateTimeField,
+				DisableLastTransitionTime: true,
+				Clock:                     clock.NewFakeClock(now),
+			})
+			Expect(acc.Update(&cond, UpdateStatus(corev1.ConditionFalse))).To(Succeed())
+
+			Expect(acc.LastUpdateTime(cond).Time).To(BeTemporally("==", metaNow.Time))
+			Expect(cond).To(HaveKeyWithValue("lastTransitionTime", "1970-01-01T00:00:01Z"))
+		})
+
+		It("should not modify the condition if an update errors", func() {
+			Expect(acc.Update(&cond, UpdateStatus(corev1.ConditionFalse), failingUpdate{})).NotTo(Succeed())
+			Expect(acc.Status(cond)).To(Equal(corev1.ConditionTrue))
+		})
+	})
+
+	Describe("FindObject", func() {
+		It("should find conditions in the object", func() {
+			found, ok, err := acc.FindObject(obj, "Ready")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(found).To(Equal(cond))
+
+			_, ok, err = acc.FindObject(obj, "Synced")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeFalse())
+
+			Expect(acc.FindObjectStatus(obj, "Synced")).To(Equal(corev1.ConditionUnknown))
+		})
+
+		It("should error if the conditions are malformed", func() {
+			obj.Object["status"] = map[string]interface{}{"conditions": []interface{}{"foo"}}
+			_, _, err := acc.FindObject(obj, "Ready")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("UpdateObject", func() {
+		It("should update an existing condition in the object", func() {
+			Expect(acc.UpdateObject(obj, "Ready", UpdateStatus(corev1.ConditionFalse))).To(Succeed())
+
+			Expect(acc.FindObjectStatus(obj, "Ready")).To(Equal(corev1.ConditionFalse))
+		})
+
+		It("should append a new condition at a custom path", func() {
+			acc := NewUnstructuredAccessor(UnstructuredAccessorOptions{
+				Path:  []string{"status", "health"},
+				Clock: clock.NewFakeClock(now),
+			})
+			acc.MustUpdateObject(obj, "Healthy", UpdateStatus(corev1.ConditionTrue), UpdateReason("Healthy"))
+
+			conds, found, err := unstructured.NestedSlice(obj.Object, "status", "health")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(conds).To(Equal([]interface{}{
+				map[string]interface{}{
+					"type":               "Healthy",
+					"status":             "True",
+					"reason":             "Healthy",
+					"lastTransitionTime": metaNow.UTC().Format(time.RFC3339),
+				},
+			}))
+		})
+
+		It("should normalize numbers that are not int64", func() {
+			cond["observedGeneration"] = 2
+			Expect(acc.UpdateObject(obj, "Ready", UpdateStatus(corev1.ConditionFalse))).To(Succeed())
+
+			found, ok, err := acc.FindObject(obj, "Ready")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(found).To(HaveKeyWithValue("observedGeneration", int64(2)))
+			Expect(acc.Status(found)).To(Equal(corev1.ConditionFalse))
+		})
+
+		It("should error on values that cannot be represented in an unstructured object", func() {
+			cond["observedGeneration"] = struct{}{}
+			Expect(acc.UpdateObject(obj, "Ready", UpdateStatus(corev1.ConditionFalse))).To(MatchError(ContainSubstring("unsupported type")))
+			Expect(acc.FindObjectStatus(obj, "Ready")).To(Equal(corev1.ConditionTrue))
+		})
+
+		It("should not modify the object if an update errors", func() {
+			original := obj.DeepCopy()
+			Expect(acc.UpdateObject(obj, "Ready", UpdateStatus(corev1.ConditionFalse), failingUpdate{})).NotTo(Succeed())
+			Expect(obj).To(Equal(original))
+		})
+	})
+})